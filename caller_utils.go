@@ -4,20 +4,98 @@ import (
 	"fmt"
 	"io"
 	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/pkg/errors"
 )
 
+// helpers holds the names of the functions marked by Helper, helperCount is the number of them,
+// which allows getPCFromCaller to skip looking up the names when no function is marked.
+var (
+	helpers     sync.Map
+	helperCount atomic.Int32
+)
+
+// Helper marks the calling function as an error helper function, e.g.: a dbErr(err error) error function calling
+// ErrUpdateOneFailed.Wrap, so that errors created inside it record the location of its caller instead.
+// Like testing.T.Helper, it can be called from any number of functions, and nested helpers are all skipped.
+//
+// Note: a helper function that is inlined by the compiler shares the program counter with its caller,
+// so the recorded location may still point into it, declare it with //go:noinline to avoid this.
+func Helper() {
+	pc, _, _, ok := runtime.Caller(1)
+	if !ok {
+		return
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return
+	}
+	if _, loaded := helpers.LoadOrStore(fn.Name(), struct{}{}); !loaded {
+		helperCount.Add(1)
+	}
+}
+
 // getPCFromCaller returns the program counter (PC) when the function is called.
 // The PC can be used to print the function name, file name, and line number where the error is created.
 // It returns 0 when Config.Caller is set to false.
 func getPCFromCaller() uintptr {
-	if Config.Caller {
-		if pc, _, _, ok := runtime.Caller(2); ok {
-			return pc
+	return callerPC(0, false)
+}
+
+// getPCFromCallerSkip is like getPCFromCaller, but skips another skip frames above the caller,
+// it is used by the skip-aware constructors such as WrapSkip.
+func getPCFromCallerSkip(skip int) uintptr {
+	return callerPC(skip, false)
+}
+
+// getPCFromDeferrer is like getPCFromCaller, but skips the frames of the runtime calling the constructor
+// as a deferred function, it is used by WrapDefer to record the deferring function.
+func getPCFromDeferrer() uintptr {
+	return callerPC(0, true)
+}
+
+// callerPC returns the PC of the caller of the constructor calling getPCFromCaller, getPCFromCallerSkip, or getPCFromDeferrer,
+// plus skip frames above it, and then skips the frames of the functions marked by Helper.
+// When deferred is true, the frames of the runtime directly above the constructor are skipped first.
+func callerPC(skip int, deferred bool) uintptr {
+	if !Config.Caller {
+		return 0
+	}
+
+	// Skip runtime.Callers, callerPC, getPCFromCaller, and the constructor.
+	var pcs [16]uintptr
+	n := runtime.Callers(4+skip, pcs[:])
+	if n == 0 {
+		return 0
+	}
+
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !more {
+			return frame.PC
+		}
+		if deferred && strings.HasPrefix(frame.Function, "runtime.") {
+			continue
+		}
+		deferred = false
+		if helperCount.Load() == 0 {
+			return frame.PC
+		}
+		if _, ok := helpers.Load(frame.Function); !ok {
+			return frame.PC
 		}
 	}
-	return 0
+}
+
+// FormatLayer formats err the same way as the errors created by this package,
+// error types outside this package can call it from their Format method to print their location with %+v,
+// as long as they implement the PC() uintptr method.
+func FormatLayer(err error, s fmt.State, verb rune) {
+	formatWithPC(err, s, verb)
 }
 
 // formatWithPC prints the program counter (PC) corresponding to the function, file name, and line number
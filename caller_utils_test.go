@@ -0,0 +1,75 @@
+package ppcerrors
+
+import (
+	"errors"
+	"testing"
+)
+
+var errHelperTest = NewDefinition("ErrHelperTest", "Helper test")
+
+//go:noinline
+func wrapSkipHelper(err error) error {
+	return errHelperTest.WrapSkip(1, err, "skip")
+}
+
+//go:noinline
+func markedHelper(err error) error {
+	Helper()
+	return errHelperTest.Wrap(err, "helper")
+}
+
+//go:noinline
+func nestedMarkedHelper(err error) error {
+	Helper()
+	return markedHelper(err)
+}
+
+//go:noinline
+func unmarkedHelper(err error) error {
+	return Wrap(err, "unmarked")
+}
+
+func frameFunction(err error) string {
+	for l := range Layers(err) {
+		return l.Frame.Function
+	}
+	return ""
+}
+
+func TestCallerSkip(t *testing.T) {
+	Config.Caller = true
+	defer func() { Config.Caller = false }()
+
+	const caller = "github.com/ppc-games/ppcerrors.TestCallerSkip"
+	cause := errors.New("root")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Wrap", Wrap(cause, "wrap"), caller},
+		{"WrapSkip", WrapSkip(0, cause, "wrap"), caller},
+		{"Definition.WrapSkip in a helper", wrapSkipHelper(cause), caller},
+		{"Definition.NewSkip", errHelperTest.NewSkip(0), caller},
+		{"ErrorCode.NewSkip", NewErrorCode("ErrCode", 1, "code").NewSkip(0), caller},
+		{"ErrorCode.WrapSkip", NewErrorCode("ErrCode", 1, "code").WrapSkip(0, cause), caller},
+		{"Helper", markedHelper(cause), caller},
+		{"Nested helpers", nestedMarkedHelper(cause), caller},
+		{"Unmarked helper", unmarkedHelper(cause), "github.com/ppc-games/ppcerrors.unmarkedHelper"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := frameFunction(tt.err); actual != tt.expected {
+				t.Errorf("Expected the frame function to be '%s', got '%s'", tt.expected, actual)
+			}
+		})
+	}
+
+	t.Run("Nil cause", func(t *testing.T) {
+		if WrapSkip(0, nil, "wrap") != nil || errHelperTest.WrapSkip(0, nil) != nil {
+			t.Error("Expected nil error when cause is nil")
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package ppcerrors
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+)
+
+// The standard definitions for errors common to most services, Classify wraps errors of the standard library with them.
+var (
+	ErrNotFound        = NewDefinition("ErrNotFound", "The requested resource was not found").MarkPermanent().MarkSeverity(SeverityWarn)
+	ErrCanceled        = NewDefinition("ErrCanceled", "The operation was canceled").MarkPermanent().MarkSeverity(SeverityInfo)
+	ErrTimeout         = NewDefinition("ErrTimeout", "The operation timed out").MarkRetryable()
+	ErrInvalidArgument = NewDefinition("ErrInvalidArgument", "The argument is invalid").MarkPermanent().MarkSeverity(SeverityWarn)
+	ErrEOF             = NewDefinition("ErrEOF", "Unexpected end of input").MarkPermanent()
+)
+
+// The standard error codes the standard definitions are translated to by StandardRules, their codes are HTTP status codes.
+var (
+	CodeInvalidArgument = NewErrorCode("CodeInvalidArgument", 400, "Invalid argument")
+	CodeNotFound        = NewErrorCode("CodeNotFound", 404, "Not found")
+	CodeCanceled        = NewErrorCode("CodeCanceled", 499, "Request canceled")
+	CodeInternal        = NewErrorCode("CodeInternal", 500, "Internal server error")
+	CodeTimeout         = NewErrorCode("CodeTimeout", 504, "Timeout")
+)
+
+var (
+	classifiersMu sync.RWMutex
+	// classifiers holds the functions registered by RegisterClassifier.
+	classifiers []func(err error) *Definition
+)
+
+// RegisterClassifier registers fn to be tried by Classify before the built-in mappings, in the order of registration,
+// e.g.: to map the errors of a third-party driver. fn returns nil when it does not recognize err.
+func RegisterClassifier(fn func(err error) *Definition) {
+	classifiersMu.Lock()
+	defer classifiersMu.Unlock()
+	classifiers = append(classifiers, fn)
+}
+
+// Classify wraps err with the standard definition matching it and returns the new error,
+// so that errors of the standard library can be identified by HasDefinition. The classifiers registered by
+// RegisterClassifier are tried first, then the built-in mappings:
+//   - sql.ErrNoRows and os.ErrNotExist to ErrNotFound.
+//   - context.Canceled to ErrCanceled.
+//   - context.DeadlineExceeded and net.Error whose Timeout() returns true to ErrTimeout.
+//   - io.EOF and io.ErrUnexpectedEOF to ErrEOF.
+//
+// Classify returns err unchanged when no mapping matches, or err already has the matching definition,
+// and nil when err is nil. When Config.Caller == true, the caller of Classify is recorded.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	def := classify(err)
+	if def == nil || HasDefinition(err, def) {
+		return err
+	}
+	return def.WrapSkip(1, err)
+}
+
+// classify returns the definition matching err, see Classify, or nil when no mapping matches.
+func classify(err error) *Definition {
+	classifiersMu.RLock()
+	for _, fn := range classifiers {
+		if def := fn(err); def != nil {
+			classifiersMu.RUnlock()
+			return def
+		}
+	}
+	classifiersMu.RUnlock()
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, os.ErrNotExist):
+		return ErrNotFound
+	case errors.Is(err, context.Canceled):
+		return ErrCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrTimeout
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrEOF
+	}
+	return nil
+}
+
+// StandardRules returns the Translator rules translating the standard definitions to the standard error codes,
+// e.g.: NewTranslator(append(StandardRules(), Default(CodeInternal))...).
+func StandardRules() []Rule {
+	return []Rule{
+		FromDefinition(ErrNotFound, CodeNotFound),
+		FromDefinition(ErrCanceled, CodeCanceled),
+		FromDefinition(ErrTimeout, CodeTimeout),
+		FromDefinition(ErrInvalidArgument, CodeInvalidArgument),
+		FromDefinition(ErrEOF, CodeInvalidArgument),
+	}
+}
@@ -0,0 +1,101 @@
+package ppcerrors
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected *Definition
+	}{
+		{"sql.ErrNoRows", fmt.Errorf("query user: %w", sql.ErrNoRows), ErrNotFound},
+		{"os.ErrNotExist", &fs.PathError{Op: "open", Path: "config.yaml", Err: os.ErrNotExist}, ErrNotFound},
+		{"context.Canceled", context.Canceled, ErrCanceled},
+		{"context.DeadlineExceeded", context.DeadlineExceeded, ErrTimeout},
+		{"net.Error timeout", timeoutError{}, ErrTimeout},
+		{"io.EOF", io.EOF, ErrEOF},
+		{"io.ErrUnexpectedEOF", io.ErrUnexpectedEOF, ErrEOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Classify(tt.err)
+			if !HasDefinition(err, tt.expected) {
+				t.Errorf("Expected %s, got '%v'", tt.expected.Name(), err)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Error("Expected the classified error to wrap the original error")
+			}
+		})
+	}
+
+	t.Run("Unknown errors are returned unchanged", func(t *testing.T) {
+		original := errors.New("mock mongodb error")
+		if err := Classify(original); err != original {
+			t.Errorf("Expected the original error, got '%v'", err)
+		}
+	})
+
+	t.Run("Classified errors are not wrapped again", func(t *testing.T) {
+		err := Classify(io.EOF)
+		if again := Classify(Wrap(err, "read header")); len(collectLayers(again)) != 3 {
+			t.Errorf("Expected no new layer, got '%v'", again)
+		}
+	})
+
+	t.Run("Registered classifiers come first", func(t *testing.T) {
+		errDuplicateKey := errors.New("E11000 duplicate key error")
+		errConflict := NewDefinition("ErrConflict", "The resource already exists")
+		RegisterClassifier(func(err error) *Definition {
+			if errors.Is(err, errDuplicateKey) || errors.Is(err, io.EOF) {
+				return errConflict
+			}
+			return nil
+		})
+		defer func() { classifiers = nil }()
+
+		if err := Classify(errDuplicateKey); !HasDefinition(err, errConflict) {
+			t.Errorf("Expected ErrConflict, got '%v'", err)
+		}
+		if err := Classify(io.EOF); !HasDefinition(err, errConflict) {
+			t.Errorf("Expected ErrConflict, got '%v'", err)
+		}
+	})
+
+	t.Run("Nil error", func(t *testing.T) {
+		if err := Classify(nil); err != nil {
+			t.Errorf("Expected nil, got '%v'", err)
+		}
+	})
+}
+
+func TestStandardRules(t *testing.T) {
+	translator := NewTranslator(append(StandardRules(), Default(CodeInternal))...)
+
+	_, err := translator.Translate(Classify(sql.ErrNoRows))
+	if !HasErrorCode(err, CodeNotFound) {
+		t.Errorf("Expected CodeNotFound, got '%v'", err)
+	}
+	_, err = translator.Translate(errors.New("mock mongodb error"))
+	if !HasErrorCode(err, CodeInternal) {
+		t.Errorf("Expected CodeInternal, got '%v'", err)
+	}
+}
+
+// collectLayers returns the layers of err as returned by Walk.
+func collectLayers(err error) []Layer {
+	var layers []Layer
+	for l := range Layers(err) {
+		layers = append(layers, l)
+	}
+	return layers
+}
@@ -0,0 +1,120 @@
+package ppcerrors
+
+import (
+	"reflect"
+	"strings"
+)
+
+// eachDefinition calls fn for d and each of its ancestors in turn until fn returns false.
+// Ancestors are found through the Parent() Definer method, which is implemented by *Definition.
+func eachDefinition(d Definer, fn func(Definer) bool) {
+	for d != nil && fn(d) {
+		p, ok := d.(interface{ Parent() Definer })
+		if !ok {
+			return
+		}
+		d = p.Parent()
+	}
+}
+
+// eachErrorCode calls fn for c and each of its ancestors in turn until fn returns false.
+// Ancestors are found through the Parent() ErrorCoder method, which is implemented by *ErrorCode.
+func eachErrorCode(c ErrorCoder, fn func(ErrorCoder) bool) {
+	for c != nil && fn(c) {
+		p, ok := c.(interface{ Parent() ErrorCoder })
+		if !ok {
+			return
+		}
+		c = p.Parent()
+	}
+}
+
+// definitionDescends reports whether d is target or one of target's descendants.
+func definitionDescends(d Definer, target Definer) bool {
+	found := false
+	eachDefinition(d, func(d Definer) bool {
+		found = sameDefinition(d, target)
+		return !found
+	})
+	return found
+}
+
+// errorCodeDescends reports whether c is target or one of target's descendants.
+func errorCodeDescends(c ErrorCoder, target ErrorCoder) bool {
+	found := false
+	eachErrorCode(c, func(c ErrorCoder) bool {
+		found = sameErrorCode(c, target)
+		return !found
+	})
+	return found
+}
+
+// sameDefinition reports whether a and b are the same definition, see identical.
+// A TypedDefinition is the same definition as the *Definition it embeds.
+func sameDefinition(a, b Definer) bool {
+	if a, ok := a.(interface{ definition() *Definition }); ok {
+		b, ok := b.(interface{ definition() *Definition })
+		return ok && a.definition() == b.definition()
+	}
+	return identical(a, b)
+}
+
+// sameErrorCode reports whether a and b are the same error code, see identical.
+// A TypedErrorCode is the same error code as the *ErrorCode it embeds.
+func sameErrorCode(a, b ErrorCoder) bool {
+	if a, ok := a.(interface{ errorCode() *ErrorCode }); ok {
+		b, ok := b.(interface{ errorCode() *ErrorCode })
+		return ok && a.errorCode() == b.errorCode()
+	}
+	return identical(a, b)
+}
+
+// identical reports whether a and b are equal with ==, without panicking like == does when they hold
+// the same type that is not comparable, e.g.: a struct holding a slice, such values are never identical.
+func identical(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.ValueOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
+
+// definitionName returns the name of d to be printed,
+// which is prefixed by the names of its ancestors when Config.CategoryPath is true, e.g.: ErrDB/ErrUpdateOneFailed.
+func definitionName(d Definer) string {
+	if !Config.CategoryPath {
+		return d.Name()
+	}
+
+	var names []string
+	eachDefinition(d, func(d Definer) bool {
+		names = append(names, d.Name())
+		return true
+	})
+	return joinReversed(names, Config.CategorySeparator)
+}
+
+// errorCodeName returns the name of c to be printed,
+// which is prefixed by the names of its ancestors when Config.CategoryPath is true, e.g.: ErrClient/ErrUnauthorized.
+func errorCodeName(c ErrorCoder) string {
+	if !Config.CategoryPath {
+		return c.Name()
+	}
+
+	var names []string
+	eachErrorCode(c, func(c ErrorCoder) bool {
+		names = append(names, c.Name())
+		return true
+	})
+	return joinReversed(names, Config.CategorySeparator)
+}
+
+// joinReversed joins names from the last one to the first one with sep.
+func joinReversed(names []string, sep string) string {
+	for i, j := 0, len(names)-1; i < j; i, j = i+1, j-1 {
+		names[i], names[j] = names[j], names[i]
+	}
+	return strings.Join(names, sep)
+}
@@ -0,0 +1,69 @@
+package ppcerrors
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDefinitionCategory(t *testing.T) {
+	errDB := NewDefinition("ErrDB", "Database error")
+	errUpdate := errDB.NewChild("ErrUpdate", "Update failed")
+	errUpdateOne := errUpdate.NewChild("ErrUpdateOneFailed", "db.UpdateOne failed")
+	errCache := NewDefinition("ErrCache", "Cache error")
+
+	t.Run("Test Parent", func(t *testing.T) {
+		if errUpdateOne.Parent() != errUpdate || errUpdate.Parent() != errDB || errDB.Parent() != nil {
+			t.Error("Expected Parent to return the definition NewChild was called on")
+		}
+	})
+
+	t.Run("HasDefinition matches any ancestor", func(t *testing.T) {
+		err := Wrap(errUpdateOne.Wrap(errors.New("root"), "SaveUser failed"), "outer")
+		for _, target := range []Definer{errUpdateOne, errUpdate, errDB} {
+			if !HasDefinition(err, target) {
+				t.Errorf("Expected HasDefinition to return true for %s", target.Name())
+			}
+		}
+		if HasDefinition(err, errCache) {
+			t.Error("Expected HasDefinition to return false for an unrelated definition")
+		}
+		if HasDefinition(errDB.New(), errUpdateOne) {
+			t.Error("Expected HasDefinition to return false for a descendant target")
+		}
+	})
+
+	t.Run("Print the category path", func(t *testing.T) {
+		Config.CategoryPath = true
+		defer func() { Config.CategoryPath = false }()
+
+		expected := "ErrDB/ErrUpdate/ErrUpdateOneFailed, db.UpdateOne failed, SaveUser failed"
+		if actual := errUpdateOne.New("SaveUser failed").Error(); actual != expected {
+			t.Errorf("Expected error message '%s', got '%s'", expected, actual)
+		}
+	})
+}
+
+func TestErrorCodeCategory(t *testing.T) {
+	errClient := NewErrorCode("ErrClient", 400, "Client error")
+	errUnauthorized := errClient.NewChild("ErrUnauthorized", 401, "Unauthorized")
+
+	t.Run("HasErrorCode matches any ancestor", func(t *testing.T) {
+		err := errUnauthorized.Wrap(errors.New("root"))
+		if !HasErrorCode(err, errUnauthorized) || !HasErrorCode(err, errClient) {
+			t.Error("Expected HasErrorCode to return true for the error code and its parent")
+		}
+		if HasErrorCode(errClient.New(), errUnauthorized) {
+			t.Error("Expected HasErrorCode to return false for a descendant target")
+		}
+	})
+
+	t.Run("Print the category path", func(t *testing.T) {
+		Config.CategoryPath = true
+		defer func() { Config.CategoryPath = false }()
+
+		expected := "ErrClient/ErrUnauthorized, Code=401, Msg=Unauthorized"
+		if actual := errUnauthorized.New().Error(); actual != expected {
+			t.Errorf("Expected error message '%s', got '%s'", expected, actual)
+		}
+	})
+}
@@ -0,0 +1,387 @@
+// Command ppcerrstat groups the errors found in logs by signature and prints the most frequent groups, e.g.:
+//
+//	ppcerrstat -n 5 server.log server.log.1
+//	kubectl logs deploy/server | ppcerrstat
+//
+// It recognizes errors printed in the Error() format, in the %+v format, in logfmt values such as error="...",
+// and in JSON lines with an "error" key, including those encoded by ppcerrors.MarshalJSON.
+// In the other lines, an error starts at the first layer ppcerrors.ParseText recognizes as an error code or a definition,
+// or after the leading time and level when it is preceded by messages, e.g.: "LoadRoom failed <= CodeNotFound, Code=404, ...",
+// or when the line only contains messages separated by ppcerrors.Config.ErrorChainSeparator, e.g.: "LoadRoom failed <= EOF".
+// The signature of an error is made of the names of its definitions and error codes, and the functions that created
+// its layers when they were recorded, so that errors with different messages, e.g.: different user IDs, are grouped together.
+// The time of each error is read from the RFC 3339 timestamp at the beginning of the line, the time= key of logfmt,
+// or the "time" key of JSON.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ppc-games/ppcerrors"
+)
+
+type (
+	// node is the part of a layer that takes part in the signature.
+	node struct {
+		name     string
+		function string
+	}
+
+	// entry is an error found in the logs.
+	entry struct {
+		nodes []node
+		text  string
+		time  time.Time
+	}
+
+	// group is the errors sharing the same signature.
+	group struct {
+		signature string
+		count     int
+		first     time.Time
+		last      time.Time
+		sample    string
+	}
+
+	// stats groups entries by signature.
+	stats struct {
+		groups map[string]*group
+		order  []*group
+	}
+
+	// jsonLine is the part of a JSON log line read by ppcerrstat, its error is either a string,
+	// or the object encoded by ppcerrors.MarshalJSON, which is also accepted as the whole line.
+	jsonLine struct {
+		Time  string          `json:"time"`
+		Error json.RawMessage `json:"error"`
+		jsonError
+	}
+
+	// jsonError is the part of the output of ppcerrors.MarshalJSON read by ppcerrstat.
+	jsonError struct {
+		Text   string `json:"-"`
+		Layers []struct {
+			Kind    string `json:"kind"`
+			Name    string `json:"name"`
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+			Frame   *struct {
+				Function string `json:"function"`
+			} `json:"frame"`
+		} `json:"layers"`
+	}
+)
+
+var (
+	leadingTime = regexp.MustCompile(`^\[?(\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?)`)
+	logfmtTime  = regexp.MustCompile(`(?:^|\s)time=("(?:[^"\\]|\\.)*"|\S+)`)
+	logfmtError = regexp.MustCompile(`(?:^|\s)(?:error|err)=("(?:[^"\\]|\\.)*"|\S+)`)
+	// leadingLevel matches the level following the leading time of a line, e.g.: "ERROR " or "] [WARN] ".
+	leadingLevel = regexp.MustCompile(`^\]?\s*(?:\[?(?:DEBUG|INFO|WARN|WARNING|ERROR|FATAL|PANIC)\]?:?\s+)?`)
+)
+
+func main() {
+	top := flag.Int("n", 10, "number of groups to print")
+	flag.Parse()
+
+	var in io.Reader = os.Stdin
+	if flag.NArg() > 0 {
+		readers := make([]io.Reader, 0, flag.NArg())
+		for _, name := range flag.Args() {
+			f, err := os.Open(name)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "ppcerrstat:", err)
+				os.Exit(1)
+			}
+			defer f.Close()
+			readers = append(readers, f)
+		}
+		in = io.MultiReader(readers...)
+	}
+
+	s := newStats()
+	if err := extract(in, s.add); err != nil {
+		fmt.Fprintln(os.Stderr, "ppcerrstat:", err)
+		os.Exit(1)
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	report(w, s.top(*top), len(s.order))
+}
+
+// extract reads r and calls fn for each error found, see the package documentation for the recognized formats.
+// The lines of an error printed with %+v are merged into one entry.
+func extract(r io.Reader, fn func(e entry)) error {
+	var block []string
+	var blockTime time.Time
+	// candidate is the message of the previous line, which starts an error printed with %+v
+	// whose outermost layer is a message when the current line is its frame or its cause.
+	var candidate string
+	var candidateTime time.Time
+	flush := func() {
+		if len(block) == 0 {
+			return
+		}
+		layers := ppcerrors.ParseText(block[0])
+		if len(block) > 1 {
+			layers = ppcerrors.ParsePlusV(strings.Join(block, "\n"))
+		}
+		fn(entry{nodes: nodesOf(layers), text: ppcerrors.TextFormatter{}.Text(layers), time: blockTime})
+		block = block[:0]
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(block) == 0 && candidate != "" && (strings.HasPrefix(line, "cause: ") || strings.HasPrefix(strings.TrimSpace(line), "at ")) {
+			block = append(block, candidate)
+			blockTime = candidateTime
+		}
+		candidate = ""
+		if len(block) > 0 && isContinuation(line) {
+			block = append(block, line)
+			continue
+		}
+		flush()
+
+		if strings.HasPrefix(strings.TrimSpace(line), "{") {
+			if e, ok := parseJSONLine(line); ok {
+				fn(e)
+			}
+			continue
+		}
+
+		t := lineTime(line)
+		text, ok := errorText(line)
+		if !ok {
+			candidate, candidateTime = messageOf(line), t
+			continue
+		}
+		if strings.Contains(text, "\n") {
+			// A logfmt value quoting a %+v output.
+			layers := ppcerrors.ParsePlusV(text)
+			fn(entry{nodes: nodesOf(layers), text: ppcerrors.TextFormatter{}.Text(layers), time: t})
+			continue
+		}
+		block = append(block, text)
+		blockTime = t
+	}
+	flush()
+	return scanner.Err()
+}
+
+// isContinuation reports whether line continues an error printed with %+v.
+func isContinuation(line string) bool {
+	return strings.HasPrefix(line, "cause: ") || strings.HasPrefix(line, "instance: ") || strings.HasPrefix(line, "build: ") ||
+		strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+}
+
+// errorText returns the error chain in line, or false when line does not contain one.
+func errorText(line string) (string, bool) {
+	if m := logfmtError.FindStringSubmatch(line); m != nil {
+		value := m[1]
+		if strings.HasPrefix(value, `"`) {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return "", false
+			}
+			value = unquoted
+		}
+		return value, value != ""
+	}
+
+	message := messageOf(line)
+	sep := ppcerrors.Config.ErrorChainSeparator
+	for i := 0; i < len(message); i++ {
+		if i > 0 && message[i-1] != ' ' || !isLayerStart(message[i:]) {
+			continue
+		}
+		if strings.HasSuffix(message[:i], sep) {
+			// Messages wrap the recognized layer.
+			return message, true
+		}
+		return message[i:], true
+	}
+	return message, strings.Contains(message, sep)
+}
+
+// messageOf returns line without its leading time and level.
+func messageOf(line string) string {
+	if loc := leadingTime.FindStringIndex(line); loc != nil {
+		line = line[loc[1]:]
+	}
+	return strings.TrimSpace(line[len(leadingLevel.FindString(line)):])
+}
+
+// isLayerStart reports whether s starts with a layer that ppcerrors.ParseText recognizes as an error code or a definition.
+func isLayerStart(s string) bool {
+	if i := strings.Index(s, ppcerrors.Config.ErrorChainSeparator); i >= 0 {
+		s = s[:i]
+	}
+	layers := ppcerrors.ParseText(s)
+	return len(layers) == 1 && (layers[0].Kind == ppcerrors.ErrorCodeLayer || layers[0].Kind == ppcerrors.DefinitionLayer)
+}
+
+// lineTime returns the time at the beginning of line or in its time= key, or the zero time.
+func lineTime(line string) time.Time {
+	if m := leadingTime.FindStringSubmatch(line); m != nil {
+		return parseTime(m[1])
+	}
+	if m := logfmtTime.FindStringSubmatch(line); m != nil {
+		value := m[1]
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		return parseTime(value)
+	}
+	return time.Time{}
+}
+
+// parseTime parses the common layouts of log timestamps, it returns the zero time when s matches none of them.
+func parseTime(s string) time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999Z07:00", "2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// parseJSONLine returns the error of a JSON log line, or false when it has none.
+func parseJSONLine(line string) (entry, bool) {
+	var jl jsonLine
+	if err := json.Unmarshal([]byte(line), &jl); err != nil || len(jl.Error) == 0 {
+		return entry{}, false
+	}
+	if err := json.Unmarshal(jl.Error, &jl.Text); err != nil {
+		var nested jsonLine
+		if err := json.Unmarshal(jl.Error, &nested); err != nil || json.Unmarshal(nested.Error, &nested.Text) != nil {
+			return entry{}, false
+		}
+		jl.jsonError = nested.jsonError
+	}
+	if jl.Text == "" {
+		return entry{}, false
+	}
+
+	e := entry{text: jl.Text, time: parseTime(jl.Time)}
+	if len(jl.Layers) == 0 {
+		e.nodes = nodesOf(ppcerrors.ParseText(jl.Text))
+		return e, true
+	}
+	for _, l := range jl.Layers {
+		n := node{}
+		switch l.Kind {
+		case "errorCode":
+			n.name = l.Name + "(" + strconv.Itoa(l.Code) + ")"
+		case "definition":
+			n.name = l.Name
+		case "message":
+			if l.Message == "" && l.Frame == nil {
+				continue
+			}
+		}
+		if l.Frame != nil {
+			n.function = l.Frame.Function
+		}
+		e.nodes = append(e.nodes, n)
+	}
+	return e, true
+}
+
+// nodesOf returns the nodes of layers.
+func nodesOf(layers []ppcerrors.Layer) []node {
+	nodes := make([]node, 0, len(layers))
+	for _, l := range layers {
+		n := node{function: l.Frame.Function}
+		switch {
+		case l.ErrorCode != nil:
+			n.name = l.ErrorCode.Name() + "(" + strconv.Itoa(l.ErrorCode.Code()) + ")"
+		case l.Definition != nil:
+			n.name = l.Definition.Name()
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+// signature returns the signature of nodes, e.g.: ErrInternalServerError(500)@handler.Login <= ErrUpdateOneFailed@db.SaveUser <= *.
+// Layers without a name are printed as *, their messages are ignored.
+func signature(nodes []node) string {
+	parts := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		part := n.name
+		if part == "" {
+			part = "*"
+		}
+		if n.function != "" {
+			part += "@" + path.Base(n.function)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " <= ")
+}
+
+func newStats() *stats {
+	return &stats{groups: make(map[string]*group)}
+}
+
+// add counts e in its group.
+func (s *stats) add(e entry) {
+	sig := signature(e.nodes)
+	g, ok := s.groups[sig]
+	if !ok {
+		g = &group{signature: sig, sample: e.text, first: e.time, last: e.time}
+		s.groups[sig] = g
+		s.order = append(s.order, g)
+	}
+	g.count++
+	if !e.time.IsZero() {
+		if g.first.IsZero() || e.time.Before(g.first) {
+			g.first = e.time
+		}
+		if e.time.After(g.last) {
+			g.last = e.time
+		}
+	}
+}
+
+// top returns the n groups with the most errors, groups with the same count are ordered by their first appearance in the logs.
+func (s *stats) top(n int) []*group {
+	groups := append([]*group(nil), s.order...)
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].count > groups[j].count })
+	if n > 0 && len(groups) > n {
+		groups = groups[:n]
+	}
+	return groups
+}
+
+// report writes groups to w, total is the number of groups found.
+func report(w io.Writer, groups []*group, total int) {
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	fmt.Fprintf(w, "%d groups, showing %d\n", total, len(groups))
+	for _, g := range groups {
+		fmt.Fprintf(w, "\n%7d  %s\n", g.count, g.signature)
+		fmt.Fprintf(w, "         first: %s  last: %s\n", formatTime(g.first), formatTime(g.last))
+		fmt.Fprintf(w, "         sample: %s\n", g.sample)
+	}
+}
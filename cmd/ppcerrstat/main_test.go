@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ppc-games/ppcerrors"
+)
+
+var (
+	errUpdateOneFailed     = ppcerrors.NewDefinition("ErrUpdateOneFailed", "db.UpdateOne failed")
+	errInternalServerError = ppcerrors.NewErrorCode("ErrInternalServerError", 500, "Internal server error")
+)
+
+//go:noinline
+func saveUser(uid int) error {
+	return ppcerrors.WithFields(errUpdateOneFailed.Wrap(errors.New("mock mongodb error"), "SaveUser failed"), ppcerrors.F("uid", uid))
+}
+
+//go:noinline
+func login(uid int) error {
+	return errInternalServerError.Wrap(saveUser(uid), fmt.Sprintf("Login failed for %d", uid))
+}
+
+func TestExtract(t *testing.T) {
+	var logs strings.Builder
+	fmt.Fprintf(&logs, "2024-05-01T10:00:00Z ERROR %v\n", login(1))
+	fmt.Fprintf(&logs, "2024-05-01T10:05:00Z INFO user logged in\n")
+	fmt.Fprintf(&logs, "time=2024-05-01T10:10:00Z level=ERROR msg=failed error=%q\n", login(2).Error())
+	data, _ := json.Marshal(map[string]interface{}{"time": "2024-05-01T10:20:00Z", "error": login(3)})
+	logs.Write(data)
+	logs.WriteString("\n")
+	fmt.Fprintf(&logs, "2024-05-01T10:30:00Z ERROR %v\n", errUpdateOneFailed.New("uid=4"))
+
+	s := newStats()
+	if err := extract(strings.NewReader(logs.String()), s.add); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Group errors by signature", func(t *testing.T) {
+		groups := s.top(10)
+		if len(groups) != 2 {
+			t.Fatalf("Expected 2 groups, got %d", len(groups))
+		}
+		g := groups[0]
+		if expected := "ErrInternalServerError(500) <= ErrUpdateOneFailed <= *"; g.signature != expected || g.count != 3 {
+			t.Errorf("Expected 3 errors with the signature '%s', got %d with '%s'", expected, g.count, g.signature)
+		}
+		if g.first.Format("15:04") != "10:00" || g.last.Format("15:04") != "10:20" {
+			t.Errorf("Expected the first and last seen to be 10:00 and 10:20, got %s and %s", g.first, g.last)
+		}
+		if g.sample != login(1).Error() {
+			t.Errorf("Expected the sample '%s', got '%s'", login(1).Error(), g.sample)
+		}
+	})
+
+	t.Run("Limit the number of groups", func(t *testing.T) {
+		if groups := s.top(1); len(groups) != 1 {
+			t.Errorf("Expected 1 group, got %d", len(groups))
+		}
+	})
+}
+
+func TestExtractPlusV(t *testing.T) {
+	ppcerrors.Config.Caller = true
+	defer func() { ppcerrors.Config.Caller = false }()
+
+	var logs strings.Builder
+	fmt.Fprintf(&logs, "2024-05-01T10:00:00Z ERROR %+v\n", login(1))
+	fmt.Fprintf(&logs, "2024-05-01T10:01:00Z ERROR %+v\n", login(2))
+	data, _ := json.Marshal(map[string]interface{}{"time": "2024-05-01T10:02:00Z", "error": login(3)})
+	logs.Write(data)
+	logs.WriteString("\n")
+
+	s := newStats()
+	if err := extract(strings.NewReader(logs.String()), s.add); err != nil {
+		t.Fatal(err)
+	}
+
+	groups := s.top(10)
+	if len(groups) != 1 || groups[0].count != 3 {
+		for _, g := range groups {
+			t.Log(g.count, g.signature)
+		}
+		t.Fatalf("Expected the 3 errors in 1 group, got %d groups", len(groups))
+	}
+	expected := "ErrInternalServerError(500)@ppcerrstat.login <= ErrUpdateOneFailed@ppcerrstat.saveUser <= *"
+	if groups[0].signature != expected {
+		t.Errorf("Expected the signature '%s', got '%s'", expected, groups[0].signature)
+	}
+
+	var out strings.Builder
+	report(&out, groups, 1)
+	if !strings.Contains(out.String(), "      3  "+expected) {
+		t.Errorf("Expected the report to contain the group, got '%s'", out.String())
+	}
+}
+
+func TestExtractOuterLayers(t *testing.T) {
+	codeNotFound := ppcerrors.NewErrorCode("CodeNotFound", 404, "Not found")
+	loadRoom := func() error { return codeNotFound.Wrap(errors.New("mock mongodb error"), "LoadRoom failed") }
+
+	signatures := func(t *testing.T, logs string) []string {
+		s := newStats()
+		if err := extract(strings.NewReader(logs), s.add); err != nil {
+			t.Fatal(err)
+		}
+		var actual []string
+		for _, g := range s.order {
+			actual = append(actual, g.signature)
+		}
+		return actual
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		format   string
+		expected string
+	}{
+		{"Error code named by any identifier", loadRoom(), "%v", "CodeNotFound(404) <= *"},
+		{"Message wrapping an error code", ppcerrors.Wrap(loadRoom(), "retrying"), "%v", "* <= CodeNotFound(404) <= *"},
+		{"Messages only", ppcerrors.Wrap(errors.New("EOF"), "LoadRoom failed"), "%v", "* <= *"},
+		{"Message printed with %+v", ppcerrors.Wrap(errors.New("EOF"), "LoadRoom failed"), "%+v", "* <= *"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := fmt.Sprintf("2024-05-01T10:00:00Z ERROR "+tt.format+"\n", tt.err)
+			if actual := signatures(t, logs); len(actual) != 1 || actual[0] != tt.expected {
+				t.Errorf("Expected the signature '%s', got %q", tt.expected, actual)
+			}
+		})
+	}
+
+	t.Run("Honour the configured separators", func(t *testing.T) {
+		ppcerrors.Config.MessagesSeparator = "; "
+		defer func() { ppcerrors.Config.MessagesSeparator = ", " }()
+
+		logs := fmt.Sprintf("2024-05-01T10:00:00Z ERROR %v\n", loadRoom())
+		if actual := signatures(t, logs); len(actual) != 1 || actual[0] != "CodeNotFound(404) <= *" {
+			t.Errorf("Expected the signature 'CodeNotFound(404) <= *', got %q", actual)
+		}
+	})
+
+	t.Run("Ignore lines without errors", func(t *testing.T) {
+		if actual := signatures(t, "2024-05-01T10:05:00Z INFO user logged in\n"); len(actual) != 0 {
+			t.Errorf("Expected no error, got %q", actual)
+		}
+	})
+}
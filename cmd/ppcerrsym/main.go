@@ -0,0 +1,358 @@
+// Command ppcerrsym symbolizes the raw program counters printed by ppcerrors when Config.RawPC is true,
+// using the unstripped binary the logging binary was built from, e.g.:
+//
+//	ppcerrsym -binary ./server.unstripped < error.log
+//
+// Each "at 0x<PC>" line is replaced by the function, file, and line number, in the same format as %+v:
+//
+//	ErrInternalServerError, Code=500, Msg=Internal server error, Login failed
+//	    at github.com/ppc-games/ppcerrors_test.Login
+//	        /Users/liangrui/Projects/go/ppcerrors/example_test.go:27
+//
+// The "build: <build ID> anchor: 0x<address>" line following the layers is used to check the binary is the right one,
+// and to adjust the program counters of position-independent executables. Lines that only contain a program counter,
+// e.g.: extracted from the JSON output, are symbolized too, see the -anchor flag.
+//
+// The single-line outputs of CompactFormatter and LogfmtFormatter are symbolized in place: "[0x<PC>]" is replaced by
+// the base file name and line number, and err.<index>.pc by err.<index>.func and err.<index>.file.
+//
+// The binary must not be built with -w, otherwise the frames of inlined functions are attributed to their callers.
+package main
+
+import (
+	"bufio"
+	"debug/dwarf"
+	"debug/elf"
+	"debug/gosym"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ppc-games/ppcerrors/internal/buildid"
+	"github.com/ppc-games/ppcerrors/internal/logfmt"
+)
+
+// anchorFunc is the function whose address is printed as the anchor by ppcerrors.
+const anchorFunc = "github.com/ppc-games/ppcerrors.rawPCAnchor"
+
+var (
+	atLine    = regexp.MustCompile(`^(\s*)at (0x[0-9a-fA-F]+)\s*$`)
+	pcLine    = regexp.MustCompile(`^(\s*)(0x[0-9a-fA-F]+)\s*$`)
+	compactPC = regexp.MustCompile(`\[(0x[0-9a-fA-F]+)\]`)
+	logfmtPC  = regexp.MustCompile(`\b(err\.\d+\.)pc=(0x[0-9a-fA-F]+)`)
+	// trailers match the build ID and the anchor printed by TextFormatter, CompactFormatter, and LogfmtFormatter.
+	trailers = []*regexp.Regexp{
+		regexp.MustCompile(`^build: (\S*) anchor: (0x[0-9a-fA-F]+)\s*$`),
+		regexp.MustCompile(`\(build: (\S*) anchor: (0x[0-9a-fA-F]+)\)`),
+		regexp.MustCompile(`\berr\.build=(\S*) err\.anchor=(0x[0-9a-fA-F]+)`),
+	}
+)
+
+// maxPending is the number of lines buffered after a program counter when no build line follows,
+// e.g.: program counters extracted from the JSON output, before they are relocated according to the -anchor flag.
+const maxPending = 1000
+
+// symbolizer maps the program counters of a binary to functions, files, and line numbers.
+// The line table provides the files and line numbers, and the DWARF data, when the binary has it,
+// provides the names of inlined functions, which the line table attributes to the function they are inlined into.
+type symbolizer struct {
+	table   *gosym.Table
+	dwarf   *dwarf.Data
+	names   map[dwarf.Offset]string
+	buildID string
+	// anchor is the address of anchorFunc in the binary, 0 when the binary does not contain it.
+	anchor uint64
+	// force symbolizes blocks whose build ID does not match the binary.
+	force bool
+}
+
+func main() {
+	binary := flag.String("binary", "", "path of the unstripped binary the logs were written by (required)")
+	anchor := flag.String("anchor", "", "runtime address of the anchor for program counters not followed by a build line, e.g.: 0x4d2a60")
+	force := flag.Bool("force", false, "symbolize even when the build ID does not match the binary")
+	flag.Parse()
+
+	if *binary == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	s, err := newSymbolizer(*binary)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ppcerrsym:", err)
+		os.Exit(1)
+	}
+	s.force = *force
+
+	var in io.Reader = os.Stdin
+	if flag.NArg() > 0 {
+		readers := make([]io.Reader, 0, flag.NArg())
+		for _, name := range flag.Args() {
+			f, err := os.Open(name)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "ppcerrsym:", err)
+				os.Exit(1)
+			}
+			defer f.Close()
+			readers = append(readers, f)
+		}
+		in = io.MultiReader(readers...)
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	if err := s.symbolize(in, w, *anchor); err != nil {
+		w.Flush()
+		fmt.Fprintln(os.Stderr, "ppcerrsym:", err)
+		os.Exit(1)
+	}
+}
+
+// newSymbolizer loads the line table of the ELF binary at path.
+func newSymbolizer(path string) (*symbolizer, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	pclntab := f.Section(".gopclntab")
+	text := f.Section(".text")
+	if pclntab == nil || text == nil {
+		return nil, errors.New(path + ": no .gopclntab or .text section, is it a Go binary?")
+	}
+	pcln, err := pclntab.Data()
+	if err != nil {
+		return nil, err
+	}
+	var symtab []byte
+	if s := f.Section(".gosymtab"); s != nil {
+		if symtab, err = s.Data(); err != nil {
+			return nil, err
+		}
+	}
+	table, err := gosym.NewTable(symtab, gosym.NewLineTable(pcln, text.Addr))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &symbolizer{table: table, names: make(map[dwarf.Offset]string)}
+	s.buildID, _ = buildid.FromELF(f)
+	if d, err := f.DWARF(); err == nil {
+		s.dwarf = d
+	}
+	if fn := table.LookupFunc(anchorFunc); fn != nil {
+		s.anchor = fn.Entry
+	}
+	return s, nil
+}
+
+// frame returns the function, file, and line number of the raw program counter pc, which is relocated by offset,
+// or false when pc is not in the binary.
+func (s *symbolizer) frame(raw string, offset uint64) (string, string, int, bool) {
+	pc, err := strconv.ParseUint(raw, 0, 64)
+	if err != nil {
+		return "", "", 0, false
+	}
+	// pc is a return address, pc-1 is the call instruction, as done by runtime.CallersFrames.
+	pc = pc - offset - 1
+	file, line, fn := s.table.PCToLine(pc)
+	if fn == nil {
+		return "", "", 0, false
+	}
+	name := s.inlinedFunc(pc)
+	if name == "" {
+		name = fn.Name
+	}
+	return name, file, line, true
+}
+
+// inlinedFunc returns the name of the innermost function inlined at pc according to the DWARF data,
+// or "" when pc is not in an inlined function or the binary has no DWARF data.
+func (s *symbolizer) inlinedFunc(pc uint64) string {
+	if s.dwarf == nil {
+		return ""
+	}
+	r := s.dwarf.Reader()
+	if _, err := r.SeekPC(pc); err != nil {
+		return ""
+	}
+
+	name := ""
+	depth := 0
+	for {
+		e, err := r.Next()
+		if err != nil || e == nil {
+			break
+		}
+		if e.Tag == 0 {
+			if depth == 0 {
+				break
+			}
+			depth--
+			continue
+		}
+
+		contains := false
+		if e.Tag == dwarf.TagSubprogram || e.Tag == dwarf.TagInlinedSubroutine {
+			ranges, _ := s.dwarf.Ranges(e)
+			for _, rg := range ranges {
+				if pc >= rg[0] && pc < rg[1] {
+					contains = true
+					break
+				}
+			}
+		}
+		if contains && e.Tag == dwarf.TagInlinedSubroutine {
+			if origin, ok := e.Val(dwarf.AttrAbstractOrigin).(dwarf.Offset); ok {
+				name = s.nameAt(origin)
+			}
+		}
+		if e.Children {
+			if contains {
+				depth++
+			} else {
+				r.SkipChildren()
+			}
+		}
+	}
+	return name
+}
+
+// nameAt returns the name of the DWARF entry at off.
+func (s *symbolizer) nameAt(off dwarf.Offset) string {
+	if name, ok := s.names[off]; ok {
+		return name
+	}
+	r := s.dwarf.Reader()
+	r.Seek(off)
+	name := ""
+	if e, err := r.Next(); err == nil && e != nil {
+		name, _ = e.Val(dwarf.AttrName).(string)
+	}
+	s.names[off] = name
+	return name
+}
+
+// offset returns how far the binary was relocated when it was loaded, given the runtime address of the anchor.
+func (s *symbolizer) offset(anchor string) (uint64, error) {
+	if anchor == "" || s.anchor == 0 {
+		return 0, nil
+	}
+	addr, err := strconv.ParseUint(anchor, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid anchor %q: %w", anchor, err)
+	}
+	return addr - s.anchor, nil
+}
+
+// symbolize copies r to w, replacing the raw program counters by their frames.
+// Lines are written as soon as they are read, except from the first line with a program counter until the build line
+// of its error, which tells how to relocate them. Buffered lines not followed by a build line within maxPending lines,
+// or at the end of r, are relocated according to defaultAnchor.
+func (s *symbolizer) symbolize(r io.Reader, w io.Writer, defaultAnchor string) error {
+	var pending []string
+	flush := func(anchor string, build string) error {
+		offset, err := s.offset(anchor)
+		if err != nil {
+			return err
+		}
+		mismatch := build != "" && s.buildID != "" && build != s.buildID
+		if mismatch && !s.force {
+			return fmt.Errorf("build ID %s does not match the binary %s, use -force to symbolize anyway", build, s.buildID)
+		}
+		for _, line := range pending {
+			if _, err := io.WriteString(w, s.symbolizeLine(line, offset)+"\n"); err != nil {
+				return err
+			}
+		}
+		pending = pending[:0]
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(pending) == 0 && !hasPC(line) {
+			if _, err := io.WriteString(w, line+"\n"); err != nil {
+				return err
+			}
+			continue
+		}
+
+		pending = append(pending, line)
+		if anchor, build, ok := trailerOf(line); ok {
+			if err := flush(anchor, build); err != nil {
+				return err
+			}
+		} else if len(pending) >= maxPending {
+			if err := flush(defaultAnchor, ""); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return flush(defaultAnchor, "")
+}
+
+// hasPC reports whether line contains a raw program counter in one of the formats symbolized by symbolizeLine.
+func hasPC(line string) bool {
+	return atLine.MatchString(line) || pcLine.MatchString(line) || compactPC.MatchString(line) || logfmtPC.MatchString(line)
+}
+
+// trailerOf returns the anchor and the build ID printed in line after the layers of an error, see trailers,
+// or false when line has none.
+func trailerOf(line string) (string, string, bool) {
+	for _, re := range trailers {
+		if m := re.FindStringSubmatch(line); m != nil {
+			// LogfmtFormatter quotes an empty build ID.
+			return m[2], strings.Trim(m[1], `"`), true
+		}
+	}
+	return "", "", false
+}
+
+// symbolizeLine returns line with its raw program counters replaced by their frames, in the format of the line:
+// the function, file, and line number as in %+v, the base file name and line number as in CompactFormatter,
+// or err.<index>.func and err.<index>.file as in LogfmtFormatter. Program counters not in the binary are kept.
+func (s *symbolizer) symbolizeLine(line string, offset uint64) string {
+	prefix := ""
+	m := atLine.FindStringSubmatch(line)
+	if m != nil {
+		prefix = m[1] + "at "
+	} else if m = pcLine.FindStringSubmatch(line); m != nil {
+		prefix = m[1]
+	}
+	if m != nil {
+		name, file, n, ok := s.frame(m[2], offset)
+		if !ok {
+			return line
+		}
+		return prefix + name + "\n\t" + file + ":" + strconv.Itoa(n)
+	}
+
+	line = compactPC.ReplaceAllStringFunc(line, func(match string) string {
+		_, file, n, ok := s.frame(compactPC.FindStringSubmatch(match)[1], offset)
+		if !ok {
+			return match
+		}
+		return "[" + filepath.Base(file) + ":" + strconv.Itoa(n) + "]"
+	})
+	return logfmtPC.ReplaceAllStringFunc(line, func(match string) string {
+		sm := logfmtPC.FindStringSubmatch(match)
+		name, file, n, ok := s.frame(sm[2], offset)
+		if !ok {
+			return match
+		}
+		return sm[1] + "func=" + logfmt.Value(name) + " " + sm[1] + "file=" + logfmt.Value(file+":"+strconv.Itoa(n))
+	})
+}
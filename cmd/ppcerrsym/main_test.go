@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ppc-games/ppcerrors"
+)
+
+var errUpdateOneFailed = ppcerrors.NewDefinition("ErrUpdateOneFailed", "db.UpdateOne failed")
+
+// saveUser is not inlined, because go test links without DWARF data, which is needed to name inlined functions.
+//
+//go:noinline
+func saveUser() error {
+	return errUpdateOneFailed.Wrap(errors.New("mock mongodb error"), "SaveUser failed")
+}
+
+// render returns the %+v output of err with Config.RawPC set to raw.
+func render(err error, raw bool) string {
+	ppcerrors.Config.RawPC = raw
+	defer func() { ppcerrors.Config.RawPC = false }()
+	return fmt.Sprintf("%+v", err)
+}
+
+// renderWith returns the verbose output of err by f with Config.RawPC set to raw.
+func renderWith(err error, f ppcerrors.Formatter, raw bool) string {
+	ppcerrors.Config.RawPC = raw
+	defer func() { ppcerrors.Config.RawPC = false }()
+	return ppcerrors.Render(err, f, true)
+}
+
+// lineReader returns one line per Read and records what was written to w before each Read.
+type lineReader struct {
+	lines   []string
+	w       *strings.Builder
+	written []string
+}
+
+func (r *lineReader) Read(p []byte) (int, error) {
+	if len(r.lines) == 0 {
+		return 0, io.EOF
+	}
+	r.written = append(r.written, r.w.String())
+	n := copy(p, r.lines[0])
+	r.lines = r.lines[1:]
+	return n, nil
+}
+
+func newTestSymbolizer(t *testing.T) *symbolizer {
+	if runtime.GOOS != "linux" {
+		t.Skip("Symbolization is only supported for ELF binaries")
+	}
+	path, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := newSymbolizer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSymbolize(t *testing.T) {
+	s := newTestSymbolizer(t)
+
+	ppcerrors.Config.Caller = true
+	defer func() { ppcerrors.Config.Caller = false }()
+	err := ppcerrors.Wrap(saveUser(), "Login failed")
+
+	t.Run("Same output as %+v", func(t *testing.T) {
+		raw := render(err, true)
+		if !strings.Contains(raw, "\n    at 0x") {
+			t.Fatalf("Expected raw program counters, got '%s'", raw)
+		}
+
+		var b strings.Builder
+		if e := s.symbolize(strings.NewReader(raw+"\n"), &b, ""); e != nil {
+			t.Fatal(e)
+		}
+		lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+		actual := strings.Join(lines[:len(lines)-1], "\n")
+		if expected := render(err, false); actual != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, actual)
+		}
+	})
+
+	for _, f := range []ppcerrors.Formatter{ppcerrors.CompactFormatter{}, ppcerrors.LogfmtFormatter{}} {
+		t.Run(fmt.Sprintf("Same output as %T", f), func(t *testing.T) {
+			raw := renderWith(err, f, true)
+			var b strings.Builder
+			if e := s.symbolize(strings.NewReader(raw+"\n"), &b, ""); e != nil {
+				t.Fatal(e)
+			}
+			expected := renderWith(err, f, false)
+			if actual := strings.TrimSuffix(b.String(), "\n"); !strings.HasPrefix(actual, expected) {
+				t.Errorf("Expected '%s', got '%s'", expected, actual)
+			}
+		})
+	}
+
+	t.Run("Stream lines without program counters", func(t *testing.T) {
+		var b strings.Builder
+		r := &lineReader{lines: []string{"first\n", "    at 0x1\n", "second\n"}, w: &b}
+		if e := s.symbolize(r, &b, ""); e != nil {
+			t.Fatal(e)
+		}
+		if r.written[1] != "first\n" || r.written[2] != "first\n" {
+			t.Errorf("Expected only the line before the program counter to be written, got %q", r.written)
+		}
+		if expected := "first\n    at 0x1\nsecond\n"; b.String() != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, b.String())
+		}
+	})
+
+	t.Run("The build ID matches the running binary", func(t *testing.T) {
+		if s.buildID == "" || s.buildID != ppcerrors.BuildID() {
+			t.Errorf("Expected the build ID '%s', got '%s'", ppcerrors.BuildID(), s.buildID)
+		}
+	})
+
+	t.Run("Reject another build", func(t *testing.T) {
+		input := "    at 0x401000\nbuild: another anchor: 0x401000\n"
+		var b strings.Builder
+		if e := s.symbolize(strings.NewReader(input), &b, ""); e == nil {
+			t.Error("Expected an error for a mismatched build ID")
+		}
+
+		s.force = true
+		defer func() { s.force = false }()
+		if e := s.symbolize(strings.NewReader(input), &b, ""); e != nil {
+			t.Errorf("Expected no error with -force, got '%v'", e)
+		}
+	})
+
+	t.Run("Keep lines without program counters", func(t *testing.T) {
+		input := "mock mongodb error\n    at 0x1\n"
+		var b strings.Builder
+		if e := s.symbolize(strings.NewReader(input), &b, ""); e != nil || b.String() != input {
+			t.Errorf("Expected '%s', got '%s'", input, b.String())
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package ppcerrors
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type (
+	// TaskError is the error of a task run by a Collector.
+	TaskError struct {
+		// Index of the task, in the order of the calls to Collector.Go, starting at 0.
+		Index int
+		// Label of the task given to Collector.Go, e.g.: the name of a shard.
+		Label string
+		// Err is the error returned by the task, with the fields task=<Index> and label=<Label>.
+		Err error
+	}
+
+	// CollectorConfig controls how a Collector runs its tasks. Its zero value runs every task at once and never cancels them.
+	CollectorConfig struct {
+		// Limit is the maximum number of tasks running at the same time, Collector.Go blocks until a task finishes
+		// when the limit is reached. There is no limit when it is not positive.
+		Limit int
+		// FailFast cancels the context of the Collector when the first task fails, so that the other tasks can stop early.
+		FailFast bool
+	}
+
+	// Collector runs tasks in goroutines and collects the errors of all the failing tasks, instead of only the first one,
+	// e.g.: to fan out a query to many shards.
+	//
+	//	c, ctx := ppcerrors.NewCollector(ctx, ErrQueryShardsFailed, ppcerrors.CollectorConfig{Limit: 8})
+	//	for _, shard := range shards {
+	//		c.Go(shard.Name, func() error {
+	//			return shard.Query(ctx, query)
+	//		})
+	//	}
+	//	return c.Wait()
+	//
+	// Its methods are safe for concurrent use, but Go must not be called after Wait.
+	Collector struct {
+		def    Definer
+		config CollectorConfig
+		ctx    context.Context
+		cancel context.CancelCauseFunc
+		sem    chan struct{}
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		next   int
+		errs   []TaskError
+	}
+
+	// withTasks is the cause of the error returned by Collector.Wait, each task error is one of its causes.
+	withTasks struct {
+		multiCause
+		tasks []TaskError
+	}
+)
+
+// NewCollector creates a Collector whose errors are wrapped in the definition def, and a context derived from ctx
+// that is canceled when Wait returns, or when the first task fails if config.FailFast is true,
+// in which case context.Cause returns the error of that task.
+func NewCollector(ctx context.Context, def Definer, config CollectorConfig) (*Collector, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	c := &Collector{def: def, config: config, ctx: ctx, cancel: cancel}
+	if config.Limit > 0 {
+		c.sem = make(chan struct{}, config.Limit)
+	}
+	return c, ctx
+}
+
+// Go runs fn in a new goroutine as the task labeled label, and records the error it returns.
+// When the limit of the Collector is reached, Go blocks until a task finishes.
+// When config.FailFast is true, tasks that have not started when the context is canceled are skipped, including those
+// Go is blocked on, their error is the error of the context, and the errors of the tasks failing with context.Canceled
+// after another task failed are ignored.
+func (c *Collector) Go(label string, fn func() error) {
+	c.mu.Lock()
+	index := c.next
+	c.next++
+	c.mu.Unlock()
+
+	if c.sem != nil && !c.acquire() {
+		c.record(index, label, c.ctx.Err())
+		return
+	}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		if c.sem != nil {
+			defer func() { <-c.sem }()
+		}
+		if c.config.FailFast && c.ctx.Err() != nil {
+			c.record(index, label, c.ctx.Err())
+			return
+		}
+		c.record(index, label, fn())
+	}()
+}
+
+// acquire waits until fewer tasks than the limit are running, it returns false when config.FailFast is true
+// and the context is canceled first.
+func (c *Collector) acquire() bool {
+	if !c.config.FailFast {
+		c.sem <- struct{}{}
+		return true
+	}
+	select {
+	case c.sem <- struct{}{}:
+		return true
+	case <-c.ctx.Done():
+		return false
+	}
+}
+
+// record records the error err of the task index labeled label, see Go. It does nothing when err is nil.
+func (c *Collector) record(index int, label string, err error) {
+	if err == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.config.FailFast && len(c.errs) > 0 && errors.Is(err, context.Canceled) {
+		return
+	}
+	err = WithFields(err, F("task", index), F("label", label))
+	c.errs = append(c.errs, TaskError{Index: index, Label: label, Err: err})
+	if c.config.FailFast {
+		c.cancel(err)
+	}
+}
+
+// Wait waits for all the tasks to finish and cancels the context of the Collector. It returns nil when no task failed,
+// otherwise an error of the definition of the Collector, whose causes are the task errors ordered by index,
+// so that HasDefinition reports the definitions of any of them and TaskErrors returns them.
+// The messages parameter is used as in Definition.Wrap, and when Config.Caller == true, the caller of Wait is recorded.
+func (c *Collector) Wait(messages ...string) error {
+	c.wg.Wait()
+	c.cancel(context.Canceled)
+
+	c.mu.Lock()
+	errs := append([]TaskError(nil), c.errs...)
+	c.mu.Unlock()
+	if len(errs) == 0 {
+		return nil
+	}
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Index < errs[j].Index })
+	taskErrs := make([]error, 0, len(errs))
+	for _, te := range errs {
+		taskErrs = append(taskErrs, te.Err)
+	}
+	cause := &withTasks{multiCause: multiCause{label: "task", errs: taskErrs}, tasks: errs}
+	return created(&withCause{
+		error: &withDefinition{
+			def:   c.def,
+			msg:   strings.Join(messages, Config.MessagesSeparator),
+			pc:    getPCFromCaller(),
+			attrs: newAttrs(cause),
+		},
+		cause: cause,
+	}, cause)
+}
+
+// TaskErrors returns the task errors of the error returned by Collector.Wait in err's chain, or nil when there is none.
+func TaskErrors(err error) []TaskError {
+	var e *withTasks
+	if As(err, &e) {
+		return e.tasks
+	}
+	return nil
+}
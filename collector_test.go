@@ -0,0 +1,175 @@
+package ppcerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCollector(t *testing.T) {
+	errQueryShardsFailed := NewDefinition("ErrQueryShardsFailed", "Query shards failed")
+	errShardDown := NewDefinition("ErrShardDown", "Shard is down")
+	errUnknown := NewDefinition("ErrUnknown", "Unknown error")
+
+	t.Run("No failure", func(t *testing.T) {
+		c, ctx := NewCollector(context.Background(), errQueryShardsFailed, CollectorConfig{})
+		for i := 0; i < 3; i++ {
+			c.Go("shard-"+strconv.Itoa(i), func() error { return nil })
+		}
+		if err := c.Wait(); err != nil {
+			t.Errorf("Expected nil, got %v", err)
+		}
+		if ctx.Err() == nil {
+			t.Error("Expected the context to be canceled by Wait")
+		}
+	})
+
+	t.Run("Collect every failure", func(t *testing.T) {
+		c, _ := NewCollector(context.Background(), errQueryShardsFailed, CollectorConfig{})
+		for i := 0; i < 5; i++ {
+			c.Go("shard-"+strconv.Itoa(i), func() error {
+				if i%2 == 0 {
+					return nil
+				}
+				time.Sleep(time.Duration(5-i) * time.Millisecond)
+				return errShardDown.New("ping failed")
+			})
+		}
+		err := Wrap(c.Wait("search failed"), "Search failed")
+
+		expected := "Search failed <= ErrQueryShardsFailed, Query shards failed, search failed <= " +
+			"ErrShardDown, Shard is down, ping failed, task=1, label=shard-1; ErrShardDown, Shard is down, ping failed, task=3, label=shard-3"
+		if err.Error() != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, err.Error())
+		}
+
+		taskErrors := TaskErrors(err)
+		if len(taskErrors) != 2 || taskErrors[0].Index != 1 || taskErrors[0].Label != "shard-1" || taskErrors[1].Index != 3 {
+			t.Errorf("Expected the tasks 1 and 3 ordered by index, got %v", taskErrors)
+		}
+		if !HasDefinition(err, errQueryShardsFailed) || !HasDefinition(err, errShardDown) || HasDefinition(err, errUnknown) {
+			t.Errorf("Expected the definitions of the collector and of the tasks, got %v", err)
+		}
+	})
+
+	t.Run("Print every failure with %+v", func(t *testing.T) {
+		c, _ := NewCollector(context.Background(), errQueryShardsFailed, CollectorConfig{})
+		c.Go("shard-0", func() error { return errShardDown.Wrap(errors.New("connection refused")) })
+		c.Go("shard-1", func() error { return errors.New("timeout") })
+
+		output := fmt.Sprintf("%+v", c.Wait())
+		if !strings.Contains(output, "task: ErrShardDown, Shard is down, task=0, label=shard-0\ncause: connection refused") ||
+			!strings.Contains(output, "task: task=1, label=shard-1\ncause: timeout") {
+			t.Errorf("Expected each task error and its chain, got '%s'", output)
+		}
+	})
+
+	t.Run("Limit the running tasks", func(t *testing.T) {
+		c, _ := NewCollector(context.Background(), errQueryShardsFailed, CollectorConfig{Limit: 2})
+		var running, peak atomic.Int32
+		for i := 0; i < 10; i++ {
+			c.Go("shard-"+strconv.Itoa(i), func() error {
+				n := running.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				running.Add(-1)
+				return nil
+			})
+		}
+		if err := c.Wait(); err != nil {
+			t.Errorf("Expected nil, got %v", err)
+		}
+		if peak.Load() > 2 {
+			t.Errorf("Expected at most 2 running tasks, got %d", peak.Load())
+		}
+	})
+
+	t.Run("Fail fast", func(t *testing.T) {
+		c, ctx := NewCollector(context.Background(), errQueryShardsFailed, CollectorConfig{Limit: 2, FailFast: true})
+		var started atomic.Int32
+		ready := make(chan struct{})
+		c.Go("shard-0", func() error {
+			started.Add(1)
+			<-ready
+			return errShardDown.New()
+		})
+		c.Go("shard-1", func() error {
+			started.Add(1)
+			close(ready)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		c.Go("shard-2", func() error {
+			started.Add(1)
+			return nil
+		})
+		err := c.Wait()
+
+		taskErrors := TaskErrors(err)
+		if len(taskErrors) != 1 || taskErrors[0].Label != "shard-0" {
+			t.Errorf("Expected only the error of shard-0, got %v", taskErrors)
+		}
+		if !HasDefinition(context.Cause(ctx), errShardDown) {
+			t.Errorf("Expected the cause of the cancellation to be ErrShardDown, got %v", context.Cause(ctx))
+		}
+		if started.Load() != 2 {
+			t.Errorf("Expected shard-2 to be skipped, got %d tasks started", started.Load())
+		}
+	})
+
+	t.Run("Stop waiting for the limit when canceled", func(t *testing.T) {
+		parent, cancel := context.WithCancel(context.Background())
+		c, _ := NewCollector(parent, errQueryShardsFailed, CollectorConfig{Limit: 1, FailFast: true})
+		running, release := make(chan struct{}), make(chan struct{})
+		c.Go("shard-0", func() error {
+			close(running)
+			<-release
+			return nil
+		})
+		<-running
+
+		var started atomic.Bool
+		returned := make(chan struct{})
+		go func() {
+			c.Go("shard-1", func() error {
+				started.Store(true)
+				return nil
+			})
+			close(returned)
+		}()
+		cancel()
+		select {
+		case <-returned:
+		case <-time.After(time.Second):
+			close(release)
+			t.Fatal("Expected Go to return when the context is canceled")
+		}
+		close(release)
+
+		taskErrors := TaskErrors(c.Wait())
+		if started.Load() || len(taskErrors) != 1 || taskErrors[0].Label != "shard-1" || !errors.Is(taskErrors[0].Err, context.Canceled) {
+			t.Errorf("Expected shard-1 to be skipped with context.Canceled, got %v", taskErrors)
+		}
+	})
+
+	t.Run("Keep the cancellations of the parent context", func(t *testing.T) {
+		parent, cancel := context.WithCancel(context.Background())
+		cancel()
+		c, ctx := NewCollector(parent, errQueryShardsFailed, CollectorConfig{FailFast: true})
+		c.Go("shard-0", func() error { return ctx.Err() })
+
+		if err := c.Wait(); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+}
@@ -1,5 +1,10 @@
 package ppcerrors
 
+import (
+	"io"
+	"os"
+)
+
 // Config defines all modifiable configuration items.
 var Config = struct {
 	// Used to distinguish which package the configuration comes from when printing logs
@@ -10,9 +15,46 @@ var Config = struct {
 	MessagesSeparator string
 	// Separator connecting two errors in the error chain
 	ErrorChainSeparator string
+	// Whether to print the names of a definition's or error code's ancestors before its own name, default: false.
+	// e.g.: ErrDB/ErrUpdateOneFailed, db.UpdateOne failed.
+	CategoryPath bool
+	// Separator connecting two names in the category path
+	CategorySeparator string
+	// Severity of errors that no layer decides the severity of, see SeverityOf, default: SeverityError.
+	DefaultSeverity Severity
+	// Message returned by PublicMessage when no layer provides a client-safe message
+	DefaultPublicMessage string
+	// Value printed in place of the value of a sensitive field, see RegisterSensitiveKeys
+	RedactedValue string
+	// Formatter rendering errors that have no formatter attached by WithFormatter, default: TextFormatter.
+	Formatter Formatter
+	// Whether to record the creation time of each layer, which %+v and JSON print, default: false.
+	Timestamps bool
+	// Whether %+v, the verbose output of every built-in Formatter, and JSON print raw program counters instead of function names, file names, and line numbers,
+	// followed by the build ID of the binary, so that cmd/ppcerrsym can symbolize them later with the unstripped binary, default: false.
+	RawPC bool
+	// Generator of the instance IDs of errors, e.g.: TimeOrderedID, default: nil, which disables instance IDs.
+	InstanceID func() string
+	// Whether Exit prints errors with %+v instead of a single line by CompactFormatter, default: false.
+	ExitVerbose bool
+	// Writer Exit prints errors to, default: os.Stderr.
+	ExitOutput io.Writer
+	// Function Exit ends the process with, it can be replaced in tests, default: os.Exit.
+	ExitFunc func(code int)
 }{
-	Package:             "ppcerrors",
-	Caller:              false,
-	MessagesSeparator:   ", ",
-	ErrorChainSeparator: " <= ",
+	Package:              "ppcerrors",
+	Caller:               false,
+	MessagesSeparator:    ", ",
+	ErrorChainSeparator:  " <= ",
+	CategoryPath:         false,
+	CategorySeparator:    "/",
+	DefaultSeverity:      SeverityError,
+	DefaultPublicMessage: "Internal server error",
+	RedactedValue:        "[REDACTED]",
+	Formatter:            TextFormatter{},
+	Timestamps:           false,
+	RawPC:                false,
+	ExitVerbose:          false,
+	ExitOutput:           os.Stderr,
+	ExitFunc:             os.Exit,
 }
@@ -2,40 +2,110 @@ package ppcerrors
 
 import "strings"
 
-// definition defines an error with a name and description.
-// name is the name of the definition, eg: "ErrNotFound".
-// desc is the description of the definition, eg: "The requested resource was not found".
-type definition struct {
-	name string
-	desc string
-}
+type (
+	// Definer interface defines the methods that an error definition must implement.
+	// It is implemented by *Definition, and can be implemented by types outside this package
+	// to be recognized by HasDefinition and Walk. Definitions are matched with ==, so implementations should be
+	// pointers or comparable types, a value that is not comparable, e.g.: a struct holding a slice, never matches.
+	Definer interface {
+		Name() string
+		Desc() string
+	}
+
+	// Definition defines an error with a name and description.
+	// name is the name of the definition, eg: "ErrNotFound".
+	// desc is the description of the definition, eg: "The requested resource was not found".
+	// parent is the category the definition belongs to, eg: ErrDB, nil for a top-level definition.
+	Definition struct {
+		name     string
+		desc     string
+		parent   Definer
+		retry    Retryability
+		severity Severity
+	}
+)
 
 // NewDefinition creates and returns a pointer to an error definition instance.
-func NewDefinition(name string, desc string) *definition {
-	return &definition{
+func NewDefinition(name string, desc string) *Definition {
+	return &Definition{
 		name: name,
 		desc: desc,
 	}
 }
 
-func (d *definition) Name() string {
+func (d *Definition) Name() string {
 	return d.name
 }
 
-func (d *definition) Desc() string {
+func (d *Definition) Desc() string {
 	return d.desc
 }
 
+// definition returns d itself, it is promoted to TypedDefinition, so that a typed definition and the *Definition
+// it embeds are the same definition for HasDefinition, including the children and the errors created by promoted methods.
+func (d *Definition) definition() *Definition {
+	return d
+}
+
+// Parent returns the definition d was created from by NewChild, or nil for a top-level definition.
+func (d *Definition) Parent() Definer {
+	return d.parent
+}
+
+// Retryability returns whether errors created from d are transient,
+// RetryUnspecified means the decision is inherited from the parent of d.
+func (d *Definition) Retryability() Retryability {
+	return d.retry
+}
+
+// MarkRetryable marks errors created from d and its descendants as transient and returns d,
+// it is meant to be called when d is declared, e.g.: NewDefinition(...).MarkRetryable().
+func (d *Definition) MarkRetryable() *Definition {
+	d.retry = RetryAllowed
+	return d
+}
+
+// MarkPermanent marks errors created from d and its descendants as permanent and returns d,
+// it is meant to be called when d is declared, e.g.: NewDefinition(...).MarkPermanent().
+func (d *Definition) MarkPermanent() *Definition {
+	d.retry = RetryForbidden
+	return d
+}
+
+// Severity returns the severity of errors created from d,
+// SeverityUnspecified means the severity is inherited from the parent of d.
+func (d *Definition) Severity() Severity {
+	return d.severity
+}
+
+// MarkSeverity sets the severity of errors created from d and its descendants and returns d,
+// it is meant to be called when d is declared, e.g.: NewDefinition(...).MarkSeverity(SeverityWarn).
+func (d *Definition) MarkSeverity(severity Severity) *Definition {
+	d.severity = severity
+	return d
+}
+
+// NewChild creates and returns a pointer to an error definition instance belonging to the category d,
+// HasDefinition(err, d) returns true for errors created from the child or any of its descendants.
+func (d *Definition) NewChild(name string, desc string) *Definition {
+	return &Definition{
+		name:   name,
+		desc:   desc,
+		parent: d,
+	}
+}
+
 // New creates a withDefinition error based on the current error definition d,
 // the messages parameter is used to attach additional error information,
 // which is concatenated with the value of Config.MessagesSeparator and stored in the msg field,
 // when Config.Caller == true, pc records the function name, file, and line number of the method that called this method.
-func (d *definition) New(messages ...string) error {
-	return &withDefinition{
-		def: d,
-		msg: strings.Join(messages, Config.MessagesSeparator),
-		pc:  getPCFromCaller(),
-	}
+func (d *Definition) New(messages ...string) error {
+	return created(&withDefinition{
+		def:   d,
+		msg:   strings.Join(messages, Config.MessagesSeparator),
+		pc:    getPCFromCaller(),
+		attrs: newAttrs(nil),
+	}, nil)
 }
 
 // Wrap wraps the given error with additional context and returns a new error.
@@ -44,17 +114,95 @@ func (d *definition) New(messages ...string) error {
 // using the Config.MessagesSeparator.
 // The returned error contains the original error, the definition, the joined messages,
 // and the program counter of the caller.
-func (d *definition) Wrap(cause error, messages ...string) error {
+func (d *Definition) Wrap(cause error, messages ...string) error {
 	if cause == nil {
 		return nil
 	}
 
-	return &withCause{
+	return created(&withCause{
 		error: &withDefinition{
-			def: d,
-			msg: strings.Join(messages, Config.MessagesSeparator),
-			pc:  getPCFromCaller(),
+			def:   d,
+			msg:   strings.Join(messages, Config.MessagesSeparator),
+			pc:    getPCFromCaller(),
+			attrs: newAttrs(cause),
 		},
 		cause: cause,
+	}, cause)
+}
+
+// NewSkip is like New, but records the location skip frames above the caller of NewSkip,
+// e.g.: NewSkip(1, ...) called in a helper function records the caller of the helper function. NewSkip(0, ...) is the same as New.
+func (d *Definition) NewSkip(skip int, messages ...string) error {
+	return created(&withDefinition{
+		def:   d,
+		msg:   strings.Join(messages, Config.MessagesSeparator),
+		pc:    getPCFromCallerSkip(skip),
+		attrs: newAttrs(nil),
+	}, nil)
+}
+
+// WrapSkip is like Wrap, but records the location skip frames above the caller of WrapSkip, see NewSkip.
+func (d *Definition) WrapSkip(skip int, cause error, messages ...string) error {
+	if cause == nil {
+		return nil
+	}
+
+	return created(&withCause{
+		error: &withDefinition{
+			def:   d,
+			msg:   strings.Join(messages, Config.MessagesSeparator),
+			pc:    getPCFromCallerSkip(skip),
+			attrs: newAttrs(cause),
+		},
+		cause: cause,
+	}, cause)
+}
+
+// WrapDefer wraps *errp in the definition like Wrap when *errp is not nil, it is meant to be deferred
+// in a function with a named error result, so that every return path is wrapped in the same definition, e.g.:
+//
+//	func LoadRoom(id string) (room *Room, err error) {
+//		defer ErrLoadFailed.WrapDefer(&err, "roomID="+id)
+//		...
+//	}
+//
+// When Config.Caller == true, *errp is left unchanged when it already has the definition created in the deferring function,
+// e.g.: by a return statement calling ErrLoadFailed.New. When Config.Caller == false, locations are unknown and *errp is always wrapped.
+// When Config.Caller == true, the deferring function is recorded rather than the runtime calling deferred functions,
+// or the function that panicked when the deferred call runs during a panic.
+func (d *Definition) WrapDefer(errp *error, messages ...string) {
+	if errp == nil || *errp == nil {
+		return
+	}
+	cause := *errp
+	pc := getPCFromDeferrer()
+	if definedAt(cause, d, pc) {
+		return
+	}
+	*errp = created(&withCause{
+		error: &withDefinition{
+			def:   d,
+			msg:   strings.Join(messages, Config.MessagesSeparator),
+			pc:    pc,
+			attrs: newAttrs(cause),
+		},
+		cause: cause,
+	}, cause)
+}
+
+// definedAt reports whether a layer in err's chain has the definition def and was created in the function of pc.
+// It returns false when pc is 0.
+func definedAt(err error, def Definer, pc uintptr) bool {
+	if pc == 0 {
+		return false
 	}
+	function := frameOf(pc).Function
+	found := false
+	walkErrors(err, func(e error) bool {
+		if p, ok := e.(interface{ PC() uintptr }); ok && sameDefinition(definitionOf(e), def) {
+			found = frameOf(p.PC()).Function == function
+		}
+		return !found
+	})
+	return found
 }
@@ -37,3 +37,66 @@ func TestDefinition(t *testing.T) {
 		}
 	})
 }
+
+func TestDefinitionWrapDefer(t *testing.T) {
+	errLoadFailed := NewDefinition("ErrLoadFailed", "Load failed")
+	errNotFound := NewDefinition("ErrNotFound", "The requested resource was not found")
+
+	loadRoom := func(closed bool, cause error) (err error) {
+		defer errLoadFailed.WrapDefer(&err, "roomID=1")
+		if closed {
+			return errLoadFailed.New("room is closed")
+		}
+		return cause
+	}
+
+	t.Run("Wrap a non-nil error", func(t *testing.T) {
+		err := loadRoom(false, errNotFound.New())
+		expected := "ErrLoadFailed, Load failed, roomID=1 <= ErrNotFound, The requested resource was not found"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected '%s', got '%v'", expected, err)
+		}
+		if err := loadRoom(false, nil); err != nil {
+			t.Errorf("Expected nil, got %v", err)
+		}
+	})
+
+	t.Run("Do not wrap the definition created in the same function", func(t *testing.T) {
+		Config.Caller = true
+		defer func() { Config.Caller = false }()
+
+		err := loadRoom(true, nil)
+		expected := "ErrLoadFailed, Load failed, room is closed"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected '%s', got '%v'", expected, err)
+		}
+	})
+
+	t.Run("Always wrap without locations", func(t *testing.T) {
+		err := loadRoom(true, nil)
+		expected := "ErrLoadFailed, Load failed, roomID=1 <= ErrLoadFailed, Load failed, room is closed"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected '%s', got '%v'", expected, err)
+		}
+	})
+
+	t.Run("Wrap the definition created in another function", func(t *testing.T) {
+		Config.Caller = true
+		defer func() { Config.Caller = false }()
+
+		err := loadRoom(false, errLoadFailed.New("shard is down"))
+		expected := "ErrLoadFailed, Load failed, roomID=1 <= ErrLoadFailed, Load failed, shard is down"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected '%s', got '%v'", expected, err)
+		}
+
+		var frame Frame
+		Walk(err, func(l Layer) bool {
+			frame = l.Frame
+			return false
+		})
+		if frame.Function != "github.com/ppc-games/ppcerrors.TestDefinitionWrapDefer.func1" {
+			t.Errorf("Expected the deferring function, got '%s'", frame.Function)
+		}
+	})
+}
@@ -4,46 +4,104 @@ import "strings"
 
 type (
 	// ErrorCoder interface defines the methods that an error code must implement.
+	// It is implemented by *ErrorCode, and can be implemented by types outside this package
+	// to be recognized by HasErrorCode and Walk. Error codes are matched with ==, so implementations should be
+	// pointers or comparable types, a value that is not comparable, e.g.: a struct holding a slice, never matches.
 	ErrorCoder interface {
 		Name() string
 		Code() int
 		Msg() string
 	}
 
-	// errorCode defines an error with a name, code, and message.
-	errorCode struct {
-		name string
-		code int
-		msg  string
+	// ErrorCode defines an error with a name, code, and message.
+	// parent is the category the error code belongs to, nil for a top-level error code.
+	ErrorCode struct {
+		name     string
+		code     int
+		msg      string
+		parent   ErrorCoder
+		retry    Retryability
+		severity Severity
 	}
 )
 
 // NewErrorCode creates and returns a pointer to an error code instance.
-func NewErrorCode(name string, code int, msg string) *errorCode {
-	return &errorCode{name: name, code: code, msg: msg}
+func NewErrorCode(name string, code int, msg string) *ErrorCode {
+	return &ErrorCode{name: name, code: code, msg: msg}
 }
 
-func (c *errorCode) Name() string {
+func (c *ErrorCode) Name() string {
 	return c.name
 }
 
-func (c *errorCode) Code() int {
+func (c *ErrorCode) Code() int {
 	return c.code
 }
 
-func (c *errorCode) Msg() string {
+func (c *ErrorCode) Msg() string {
 	return c.msg
 }
 
+// errorCode returns c itself, it is promoted to TypedErrorCode, so that a typed error code and the *ErrorCode
+// it embeds are the same error code for HasErrorCode, including the children and the errors created by promoted methods.
+func (c *ErrorCode) errorCode() *ErrorCode {
+	return c
+}
+
+// Parent returns the error code c was created from by NewChild, or nil for a top-level error code.
+func (c *ErrorCode) Parent() ErrorCoder {
+	return c.parent
+}
+
+// Retryability returns whether errors created from c are transient,
+// RetryUnspecified means the decision is inherited from the parent of c.
+func (c *ErrorCode) Retryability() Retryability {
+	return c.retry
+}
+
+// MarkRetryable marks errors created from c and its descendants as transient and returns c,
+// it is meant to be called when c is declared, e.g.: NewErrorCode(...).MarkRetryable().
+func (c *ErrorCode) MarkRetryable() *ErrorCode {
+	c.retry = RetryAllowed
+	return c
+}
+
+// MarkPermanent marks errors created from c and its descendants as permanent and returns c,
+// it is meant to be called when c is declared, e.g.: NewErrorCode(...).MarkPermanent().
+func (c *ErrorCode) MarkPermanent() *ErrorCode {
+	c.retry = RetryForbidden
+	return c
+}
+
+// Severity returns the severity of errors created from c,
+// SeverityUnspecified means the severity is inherited from the parent of c.
+func (c *ErrorCode) Severity() Severity {
+	return c.severity
+}
+
+// MarkSeverity sets the severity of errors created from c and its descendants and returns c,
+// it is meant to be called when c is declared, e.g.: NewErrorCode(...).MarkSeverity(SeverityWarn).
+func (c *ErrorCode) MarkSeverity(severity Severity) *ErrorCode {
+	c.severity = severity
+	return c
+}
+
+// NewChild creates and returns a pointer to an error code instance belonging to the category c,
+// HasErrorCode(err, c) returns true for errors created from the child or any of its descendants.
+func (c *ErrorCode) NewChild(name string, code int, msg string) *ErrorCode {
+	return &ErrorCode{name: name, code: code, msg: msg, parent: c}
+}
+
 // New creates a new error with the given messages and associates it with the error code.
 // It returns an error that implements the `error` interface,
 // when Config.Caller == true, pc records the function name, file, and line number of the method that called this method.
-func (c *errorCode) New(messages ...string) error {
-	return &withErrorCode{
+func (c *ErrorCode) New(messages ...string) error {
+	return created(&withErrorCode{
 		errCode: c,
 		msg:     strings.Join(messages, Config.MessagesSeparator),
 		pc:      getPCFromCaller(),
-	}
+		attrs:   newAttrs(nil),
+	}, nil)
 }
 
 // Wrap wraps the given error with additional context and returns a new error.
@@ -51,17 +109,46 @@ func (c *errorCode) New(messages ...string) error {
 // The additional context is specified by the messages parameter, which is joined
 // using the Config.MessagesSeparator. The function also captures the program counter (PC)
 // of the caller using the getPCFromCaller function.
-func (c *errorCode) Wrap(cause error, messages ...string) error {
+func (c *ErrorCode) Wrap(cause error, messages ...string) error {
 	if cause == nil {
 		return nil
 	}
 
-	return &withCause{
+	return created(&withCause{
 		error: &withErrorCode{
 			errCode: c,
 			msg:     strings.Join(messages, Config.MessagesSeparator),
 			pc:      getPCFromCaller(),
+			attrs:   newAttrs(cause),
 		},
 		cause: cause,
+	}, cause)
+}
+
+// NewSkip is like New, but records the location skip frames above the caller of NewSkip,
+// e.g.: NewSkip(1, ...) called in a helper function records the caller of the helper function. NewSkip(0, ...) is the same as New.
+func (c *ErrorCode) NewSkip(skip int, messages ...string) error {
+	return created(&withErrorCode{
+		errCode: c,
+		msg:     strings.Join(messages, Config.MessagesSeparator),
+		pc:      getPCFromCallerSkip(skip),
+		attrs:   newAttrs(nil),
+	}, nil)
+}
+
+// WrapSkip is like Wrap, but records the location skip frames above the caller of WrapSkip, see NewSkip.
+func (c *ErrorCode) WrapSkip(skip int, cause error, messages ...string) error {
+	if cause == nil {
+		return nil
 	}
+
+	return created(&withCause{
+		error: &withErrorCode{
+			errCode: c,
+			msg:     strings.Join(messages, Config.MessagesSeparator),
+			pc:      getPCFromCallerSkip(skip),
+			attrs:   newAttrs(cause),
+		},
+		cause: cause,
+	}, cause)
 }
@@ -0,0 +1,116 @@
+package ppcerrors
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	exitCodesMu sync.RWMutex
+	// definitionExitCodes and errorCodeExitCodes hold the exit codes registered by ExitCodeForDefinition and ExitCodeForErrorCode,
+	// keyed by definitionKey and errorCodeKey.
+	definitionExitCodes = map[Definer]int{}
+	errorCodeExitCodes  = map[ErrorCoder]int{}
+)
+
+// ExitCodeForDefinition maps the errors that contain the definition def or one of its descendants to the exit code status,
+// see ExitCode, e.g.: ExitCodeForDefinition(ErrInvalidArgument, 2) for command-line usage errors.
+// It panics when def is not comparable, see Definer.
+func ExitCodeForDefinition(def Definer, status int) {
+	key, ok := definitionKey(def)
+	if !ok {
+		panic(fmt.Sprintf("ppcerrors: ExitCodeForDefinition called with the definition %T, which is not comparable", def))
+	}
+	exitCodesMu.Lock()
+	defer exitCodesMu.Unlock()
+	definitionExitCodes[key] = status
+}
+
+// ExitCodeForErrorCode maps the errors that contain the error code code or one of its descendants to the exit code status,
+// see ExitCode. It panics when code is not comparable, see ErrorCoder.
+func ExitCodeForErrorCode(code ErrorCoder, status int) {
+	key, ok := errorCodeKey(code)
+	if !ok {
+		panic(fmt.Sprintf("ppcerrors: ExitCodeForErrorCode called with the error code %T, which is not comparable", code))
+	}
+	exitCodesMu.Lock()
+	defer exitCodesMu.Unlock()
+	errorCodeExitCodes[key] = status
+}
+
+// definitionKey returns the key of d in definitionExitCodes, which is the *Definition embedded by a TypedDefinition,
+// or d itself, see sameDefinition. It returns false when d is nil or not comparable, so that it is never used as a key.
+func definitionKey(d Definer) (Definer, bool) {
+	if t, ok := d.(interface{ definition() *Definition }); ok {
+		return t.definition(), true
+	}
+	return d, d != nil && reflect.ValueOf(d).Comparable()
+}
+
+// errorCodeKey returns the key of c in errorCodeExitCodes, which is the *ErrorCode embedded by a TypedErrorCode,
+// or c itself, see sameErrorCode. It returns false when c is nil or not comparable, so that it is never used as a key.
+func errorCodeKey(c ErrorCoder) (ErrorCoder, bool) {
+	if t, ok := c.(interface{ errorCode() *ErrorCode }); ok {
+		return t.errorCode(), true
+	}
+	return c, c != nil && reflect.ValueOf(c).Comparable()
+}
+
+// ExitCode returns the exit code of a process ending with err, so that scripts running a command can tell failures apart.
+// It looks up the exit codes registered by ExitCodeForDefinition and ExitCodeForErrorCode for each layer in err's chain,
+// from the outermost layer to the innermost one, trying the error code or definition of a layer before its ancestors.
+// ExitCode returns 0 when err is nil, and 1 when no registered exit code matches.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	exitCodesMu.RLock()
+	defer exitCodesMu.RUnlock()
+
+	status, found := 0, false
+	walkErrors(err, func(e error) bool {
+		eachErrorCode(errorCodeOf(e), func(c ErrorCoder) bool {
+			if key, ok := errorCodeKey(c); ok {
+				status, found = errorCodeExitCodes[key]
+			}
+			return !found
+		})
+		if !found {
+			eachDefinition(definitionOf(e), func(d Definer) bool {
+				if key, ok := definitionKey(d); ok {
+					status, found = definitionExitCodes[key]
+				}
+				return !found
+			})
+		}
+		return !found
+	})
+	if !found {
+		return 1
+	}
+	return status
+}
+
+// Exit prints err to Config.ExitOutput, which is os.Stderr by default, and ends the process with the exit code of err,
+// see ExitCode, by calling Config.ExitFunc, which is os.Exit by default. It is meant to end the main function of
+// command-line tools instead of log.Fatal, which always exits with 1, e.g.:
+//
+//	if err := run(); err != nil {
+//		ppcerrors.Config.ExitVerbose = *verbose
+//		ppcerrors.Exit(err)
+//	}
+//
+// err is printed with %+v when Config.ExitVerbose == true, otherwise in a single line by CompactFormatter.
+// Exit prints nothing and exits with 0 when err is nil.
+func Exit(err error) {
+	if err != nil {
+		if Config.ExitVerbose {
+			_, _ = fmt.Fprintf(Config.ExitOutput, "%+v\n", err)
+		} else {
+			_, _ = fmt.Fprintln(Config.ExitOutput, Render(err, CompactFormatter{}, false))
+		}
+	}
+	Config.ExitFunc(ExitCode(err))
+}
@@ -0,0 +1,126 @@
+package ppcerrors
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	errUsage := NewDefinition("ErrUsage", "Invalid usage")
+	errMissingFlag := errUsage.NewChild("ErrMissingFlag", "Missing flag")
+	errMigration := NewDefinition("ErrMigration", "Migration failed")
+	errConflict := NewErrorCode("ErrConflict", 409, "Conflict")
+	ExitCodeForDefinition(errUsage, 2)
+	ExitCodeForDefinition(errMigration, 3)
+	ExitCodeForErrorCode(errConflict, 4)
+
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"Nil error", nil, 0},
+		{"Unmapped error", errors.New("mock mongodb error"), 1},
+		{"Mapped definition", errMigration.New(), 3},
+		{"Descendant of a mapped definition", errMissingFlag.New("--dsn"), 2},
+		{"Mapped error code", errConflict.Wrap(errors.New("mock mongodb error")), 4},
+		{"Outermost mapping wins", errConflict.Wrap(errMigration.New()), 4},
+		{"Mapping of an inner layer", Wrap(errMigration.Wrap(errors.New("mock mongodb error")), "run failed"), 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := ExitCode(tt.err); code != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, code)
+			}
+		})
+	}
+
+	t.Run("Typed definitions and error codes", func(t *testing.T) {
+		errQuota := NewTypedDefinition[int]("ErrQuota", "Quota exceeded")
+		errLocked := NewTypedErrorCode[string]("ErrLocked", 423, "Locked")
+		ExitCodeForDefinition(errQuota.Definition, 5)
+		ExitCodeForErrorCode(errLocked, 6)
+		if code := ExitCode(errQuota.New(10)); code != 5 {
+			t.Errorf("Expected 5, got %d", code)
+		}
+		if code := ExitCode(errLocked.ErrorCode.New()); code != 6 {
+			t.Errorf("Expected 6, got %d", code)
+		}
+	})
+
+	t.Run("Definitions and error codes that are not comparable", func(t *testing.T) {
+		def := uncomparableDefinition{names: []string{"ErrCustom"}}
+		code := uncomparableErrorCode{names: []string{"ErrCustom"}}
+		err := WrapWith(WrapWith(errors.New("root"), &uncomparableDefinitionError{def: def}), &uncomparableErrorCodeError{errCode: code})
+		if status := ExitCode(err); status != 1 {
+			t.Errorf("Expected 1, got %d", status)
+		}
+
+		for name, register := range map[string]func(){
+			"ExitCodeForDefinition": func() { ExitCodeForDefinition(def, 7) },
+			"ExitCodeForErrorCode":  func() { ExitCodeForErrorCode(code, 7) },
+		} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("Expected %s to panic", name)
+					}
+				}()
+				register()
+			}()
+		}
+	})
+}
+
+func TestExit(t *testing.T) {
+	errMigration := NewDefinition("ErrMigration", "Migration failed")
+	ExitCodeForDefinition(errMigration, 3)
+
+	var output bytes.Buffer
+	var code int
+	Config.ExitOutput = &output
+	Config.ExitFunc = func(c int) { code = c }
+	defer func() {
+		Config.ExitOutput = os.Stderr
+		Config.ExitFunc = os.Exit
+	}()
+
+	t.Run("Print a single line", func(t *testing.T) {
+		output.Reset()
+		Exit(errMigration.Wrap(errors.New("mock mongodb error"), "version=42"))
+
+		expected := "ErrMigration: version=42 <= mock mongodb error\n"
+		if output.String() != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, output.String())
+		}
+		if code != 3 {
+			t.Errorf("Expected exit code 3, got %d", code)
+		}
+	})
+
+	t.Run("Print with %+v", func(t *testing.T) {
+		Config.Caller = true
+		Config.ExitVerbose = true
+		defer func() {
+			Config.Caller = false
+			Config.ExitVerbose = false
+		}()
+
+		output.Reset()
+		Exit(errMigration.New("version=42"))
+		if !strings.HasPrefix(output.String(), "ErrMigration, Migration failed, version=42\n    at github.com/ppc-games/ppcerrors.TestExit.func4") {
+			t.Errorf("Expected the location of the error, got '%s'", output.String())
+		}
+	})
+
+	t.Run("Nil error", func(t *testing.T) {
+		output.Reset()
+		Exit(nil)
+		if output.Len() != 0 || code != 0 {
+			t.Errorf("Expected no output and exit code 0, got '%s' and %d", output.String(), code)
+		}
+	})
+}
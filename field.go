@@ -0,0 +1,191 @@
+package ppcerrors
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type (
+	// Field is a key-value pair attached to an error to record structured context,
+	// e.g.: F("uid", 123) is printed as uid=123.
+	Field struct {
+		Key   string
+		Value interface{}
+	}
+
+	// attrs holds the optional attributes shared by every layer created by this package.
+	attrs struct {
+		fields   []Field
+		detail   interface{}
+		retry    Retryability
+		severity Severity
+		public   string
+		format   Formatter
+		logged   bool
+		id       string
+		created  time.Time
+		// origin is the layer this one was copied from by amend, see isOrigin.
+		origin error
+		// anonymous tells the layer was added by amend to hold the attributes of a foreign error, see isEmpty.
+		anonymous bool
+	}
+)
+
+// F creates a Field with the given key and value.
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// String returns the field in the form of key=value, the value of a sensitive key is redacted, see Redact.
+func (f Field) String() string {
+	f = Redact(f)
+	return f.Key + "=" + fmt.Sprint(f.Value)
+}
+
+// newAttrs returns the attributes of a layer being created by New or Wrap with the given cause, which is nil for New.
+func newAttrs(cause error) attrs {
+	a := attrs{
+		id: newInstanceID(cause),
+	}
+	if Config.Timestamps {
+		a.created = time.Now()
+	}
+	return a
+}
+
+// withDetail returns a copy of a with the detail.
+func (a attrs) withDetail(detail interface{}) attrs {
+	a.detail = detail
+	return a
+}
+
+// withFields returns a copy of a with the fields.
+func (a attrs) withFields(fields ...Field) attrs {
+	a.fields = fields
+	return a
+}
+
+// Fields returns the fields attached to the layer.
+func (a *attrs) Fields() []Field {
+	return a.fields
+}
+
+// Detail returns the typed payload attached to the layer, see NewTypedDefinition and NewTypedErrorCode.
+func (a *attrs) Detail() interface{} {
+	return a.detail
+}
+
+// Retryability returns the retryability decided for the layer itself, see WithRetryable.
+func (a *attrs) Retryability() Retryability {
+	return a.retry
+}
+
+// Severity returns the severity decided for the layer itself, see WithSeverity.
+func (a *attrs) Severity() Severity {
+	return a.severity
+}
+
+// PublicMessage returns the client-safe message attached to the layer itself, see WithPublicMessage.
+func (a *attrs) PublicMessage() string {
+	return a.public
+}
+
+// Logged reports whether the layer has been logged, see MarkLogged.
+func (a *attrs) Logged() bool {
+	return a.logged
+}
+
+// InstanceID returns the instance ID of the layer, see InstanceID.
+func (a *attrs) InstanceID() string {
+	return a.id
+}
+
+// isAnonymous reports whether the layer was added by amend to hold the attributes of a foreign error.
+func (a *attrs) isAnonymous() bool {
+	return a.anonymous
+}
+
+// Is reports whether target is the layer this one was copied from, e.g.: by MarkLogged, see isOrigin.
+// It implements the Is interface in the errors standard library.
+func (a *attrs) Is(target error) bool {
+	return isOrigin(a.origin, target)
+}
+
+// isOrigin reports whether target is origin, or the error origin was itself copied from,
+// so that errors.Is still matches an error of this package after amend copied its outermost layer.
+func isOrigin(origin error, target error) bool {
+	if origin == nil {
+		return false
+	}
+	if origin == target {
+		return true
+	}
+	i, ok := origin.(interface{ Is(error) bool })
+	return ok && i.Is(target)
+}
+
+// Time returns the creation time of the layer, or the zero time when Config.Timestamps was false when it was created.
+func (a *attrs) Time() time.Time {
+	return a.created
+}
+
+// formatter returns the formatter attached to the layer itself, see WithFormatter.
+func (a *attrs) formatter() Formatter {
+	return a.format
+}
+
+// WithFields attaches fields to the outermost layer of err and returns the new error.
+// err itself is never modified, the outermost layer is copied before the fields are appended, and errors.Is matches the new error with err.
+// When the outermost layer of err was not created by this package, err is wrapped by a layer holding only the fields.
+// WithFields returns nil when err is nil.
+func WithFields(err error, fields ...Field) error {
+	if err == nil {
+		return nil
+	}
+	return amend(err, func(a *attrs) {
+		a.fields = append(a.fields[:len(a.fields):len(a.fields)], fields...)
+	})
+}
+
+// amend returns a copy of err whose outermost layer has its attributes modified by f, which errors.Is matches with err.
+// When the outermost layer was not created by this package, including a layer added by WrapWith,
+// err is wrapped by an anonymous withMessage layer instead.
+func amend(err error, f func(a *attrs)) error {
+	switch e := err.(type) {
+	case *withCause:
+		// A layer added by WrapWith is kept as is, and wrapped together with its causes below.
+		if isBuiltin(e.error) {
+			return &withCause{error: amend(e.error, f), cause: e.cause, origin: e}
+		}
+	case *withMessage:
+		c := *e
+		c.origin = e
+		f(&c.attrs)
+		return &c
+	case *withDefinition:
+		c := *e
+		c.origin = e
+		f(&c.attrs)
+		return &c
+	case *withErrorCode:
+		c := *e
+		c.origin = e
+		f(&c.attrs)
+		return &c
+	}
+
+	l := &withMessage{attrs: attrs{anonymous: true}}
+	f(&l.attrs)
+	return &withCause{error: l, cause: err}
+}
+
+// writeFields appends fields to b, each of which is preceded by Config.MessagesSeparator unless b is empty.
+func writeFields(b *strings.Builder, fields []Field) {
+	for _, f := range fields {
+		if b.Len() > 0 {
+			b.WriteString(Config.MessagesSeparator)
+		}
+		b.WriteString(f.String())
+	}
+}
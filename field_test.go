@@ -0,0 +1,84 @@
+package ppcerrors
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithFields(t *testing.T) {
+	t.Run("Attach fields to a layer created by this package", func(t *testing.T) {
+		def := NewDefinition("ErrLoadFailed", "Load failed")
+		err := WithFields(def.Wrap(errors.New("root"), "LoadRoom"), F("roomID", 42), F("uid", "u1"))
+
+		expected := "ErrLoadFailed, Load failed, LoadRoom, roomID=42, uid=u1 <= root"
+		if err.Error() != expected {
+			t.Errorf("Expected error message '%s', got '%s'", expected, err.Error())
+		}
+		if !HasDefinition(err, def) {
+			t.Error("Expected the definition to be kept")
+		}
+	})
+
+	t.Run("Do not modify the original error", func(t *testing.T) {
+		original := Wrap(errors.New("root"), "wrapped")
+		_ = WithFields(original, F("a", 1))
+		if original.Error() != "wrapped <= root" {
+			t.Errorf("Expected the original error to be unchanged, got '%s'", original.Error())
+		}
+	})
+
+	t.Run("Wrap a foreign error", func(t *testing.T) {
+		root := errors.New("root")
+		err := WithFields(root, F("a", 1))
+		if err.Error() != "a=1 <= root" {
+			t.Errorf("Expected error message 'a=1 <= root', got '%s'", err.Error())
+		}
+		if !errors.Is(err, root) {
+			t.Error("Expected the foreign error to be the cause")
+		}
+
+		var fields []Field
+		Walk(err, func(l Layer) bool {
+			fields = append(fields, l.Fields...)
+			return true
+		})
+		if len(fields) != 1 || fields[0] != F("a", 1) {
+			t.Errorf("Expected the fields to be visible to Walk, got %v", fields)
+		}
+	})
+
+	t.Run("Keep a layer added by WrapWith", func(t *testing.T) {
+		code := customErrorCode{name: "ErrCustom", code: 600}
+		err := WrapWith(errors.New("root"), &customError{errCode: code})
+
+		tests := []struct {
+			name  string
+			amend func(err error) error
+			check func(err error) bool
+		}{
+			{"WithFields", func(err error) error { return WithFields(err, F("a", 1)) }, func(err error) bool { return err.Error() == "a=1 <= ErrCustom(600) <= root" }},
+			{"WithSeverity", func(err error) error { return WithSeverity(err, SeverityWarn) }, func(err error) bool { return SeverityOf(err) == SeverityWarn }},
+			{"WithRetryable", func(err error) error { return WithRetryable(err, true) }, IsRetryable},
+			{"WithPublicMessage", func(err error) error { return WithPublicMessage(err, "Try again") }, func(err error) bool { return PublicMessage(err) == "Try again" }},
+			{"WithFormatter", func(err error) error { return WithFormatter(err, MultiLineFormatter{}) }, func(err error) bool { return err.Error() == "ErrCustom(600)\ncause: root" }},
+			{"MarkLogged", MarkLogged, IsLogged},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				amended := tt.amend(err)
+				if !HasErrorCode(amended, code) {
+					t.Errorf("Expected the layer added by WrapWith to be kept, got '%s'", amended.Error())
+				}
+				if !tt.check(amended) {
+					t.Errorf("Expected %s to apply, got '%s'", tt.name, amended.Error())
+				}
+			})
+		}
+	})
+
+	t.Run("Nil error", func(t *testing.T) {
+		if WithFields(nil, F("a", 1)) != nil {
+			t.Error("Expected nil error, got non-nil")
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package ppcerrors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type (
+	// FingerprintOption customizes which parts of an error chain Fingerprint takes into account.
+	FingerprintOption func(o *fingerprintOptions)
+
+	fingerprintOptions struct {
+		filters   []func(l Layer) bool
+		locations bool
+	}
+)
+
+// IncludeLayers only takes the layers for which keep returns true into account, it can be given several times,
+// in which case a layer must be kept by all of them.
+func IncludeLayers(keep func(l Layer) bool) FingerprintOption {
+	return func(o *fingerprintOptions) {
+		o.filters = append(o.filters, keep)
+	}
+}
+
+// ExcludeLayers ignores the layers for which drop returns true, e.g.: the layers added by a middleware.
+func ExcludeLayers(drop func(l Layer) bool) FingerprintOption {
+	return IncludeLayers(func(l Layer) bool { return !drop(l) })
+}
+
+// WithoutLocations ignores where the layers were created, so that the same chain of definitions and error codes
+// created at different places has the same fingerprint.
+func WithoutLocations() FingerprintOption {
+	return func(o *fingerprintOptions) {
+		o.locations = false
+	}
+}
+
+// Fingerprint returns a 16-character hexadecimal hash identifying the kind of err rather than its occurrence,
+// so that errors only differing by their messages or fields, e.g.: uid=123 and uid=456, can be grouped and deduplicated.
+// It is computed from each layer in err's chain, as returned by Walk:
+//   - The names of its definition and error code, and the code of the latter.
+//   - The function, base file name, and line number where it was created, when Config.Caller was true,
+//     which are stable across restarts and builds of the same source, unlike program counters.
+//   - The Go type of errors not created by this package, instead of their messages.
+//
+// Messages, fields, details, and instance IDs are ignored. Fingerprint returns "" when err is nil.
+func Fingerprint(err error, opts ...FingerprintOption) string {
+	if err == nil {
+		return ""
+	}
+
+	o := fingerprintOptions{locations: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	h := sha256.New()
+	Walk(err, func(l Layer) bool {
+		if isEmpty(l) && l.Frame.PC == 0 {
+			return true
+		}
+		for _, keep := range o.filters {
+			if !keep(l) {
+				return true
+			}
+		}
+
+		var b strings.Builder
+		b.WriteString(l.Kind.String())
+		switch l.Kind {
+		case ErrorCodeLayer:
+			b.WriteString("|" + l.ErrorCode.Name() + "|" + strconv.Itoa(l.ErrorCode.Code()))
+		case DefinitionLayer:
+			b.WriteString("|" + l.Definition.Name())
+		case ForeignLayer:
+			b.WriteString("|" + fmt.Sprintf("%T", l.Err))
+		}
+		if o.locations && l.Frame.PC != 0 {
+			b.WriteString("|" + l.Frame.Function + "|" + filepath.Base(l.Frame.File) + ":" + strconv.Itoa(l.Frame.Line))
+		}
+		b.WriteString("\n")
+		_, _ = h.Write([]byte(b.String()))
+		return true
+	})
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
@@ -0,0 +1,67 @@
+package ppcerrors
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	errUpdateOneFailed := NewDefinition("ErrUpdateOneFailed", "db.UpdateOne failed")
+	errInternalServerError := NewErrorCode("ErrInternalServerError", 500, "Internal server error")
+
+	Config.Caller = true
+	defer func() { Config.Caller = false }()
+
+	saveUser := func(uid int) error {
+		return WithFields(errUpdateOneFailed.Wrap(errors.New("mock mongodb error"), "SaveUser failed"), F("uid", uid))
+	}
+	login := func(uid int) error {
+		return errInternalServerError.Wrap(saveUser(uid), "Login failed")
+	}
+
+	t.Run("Ignore messages and fields", func(t *testing.T) {
+		a, b := Fingerprint(login(123)), Fingerprint(login(456))
+		if a != b || len(a) != 16 {
+			t.Errorf("Expected the same 16-character fingerprint, got '%s' and '%s'", a, b)
+		}
+		if Fingerprint(errUpdateOneFailed.Wrap(errors.New("mock redis error"), "SaveUser failed")) == Fingerprint(saveUser(1)) {
+			t.Error("Expected errors created at different lines to differ")
+		}
+	})
+
+	t.Run("Different definitions differ", func(t *testing.T) {
+		errNotFound := NewDefinition("ErrNotFound", "Not found")
+		a := Fingerprint(errNotFound.New(), WithoutLocations())
+		b := Fingerprint(errUpdateOneFailed.New(), WithoutLocations())
+		if a == b {
+			t.Errorf("Expected different fingerprints, got '%s'", a)
+		}
+	})
+
+	t.Run("Without locations", func(t *testing.T) {
+		a := Fingerprint(errUpdateOneFailed.New("a"), WithoutLocations())
+		b := Fingerprint(errUpdateOneFailed.New("b"), WithoutLocations())
+		if a != b {
+			t.Errorf("Expected the same fingerprint, got '%s' and '%s'", a, b)
+		}
+	})
+
+	t.Run("Exclude layers", func(t *testing.T) {
+		outer := func(l Layer) bool { return l.ErrorCode != nil }
+		a := Fingerprint(login(1), ExcludeLayers(outer))
+		b := Fingerprint(saveUser(1))
+		if a != b {
+			t.Errorf("Expected the fingerprint of the inner error, got '%s' and '%s'", a, b)
+		}
+		only := IncludeLayers(func(l Layer) bool { return l.Kind == DefinitionLayer })
+		if Fingerprint(login(1), only, WithoutLocations()) != Fingerprint(errUpdateOneFailed.New(), WithoutLocations()) {
+			t.Error("Expected only the definition layer to be taken into account")
+		}
+	})
+
+	t.Run("Nil error", func(t *testing.T) {
+		if f := Fingerprint(nil); f != "" {
+			t.Errorf("Expected '', got '%s'", f)
+		}
+	})
+}
@@ -0,0 +1,463 @@
+package ppcerrors
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ppc-games/ppcerrors/internal/logfmt"
+)
+
+type (
+	// Formatter renders error chains, it is used by the Error method and the fmt verbs of the errors created by this package.
+	// layers are ordered from the outermost layer to the innermost one, as returned by Walk,
+	// except that the last layer may be an error not created by this package whose Error() already covers its own causes.
+	Formatter interface {
+		// Text renders layers for Error(), %s, %v, and %q, their frames only have a PC, see Frame.
+		Text(layers []Layer) string
+		// Verbose renders layers with their frames for %+v.
+		Verbose(layers []Layer) string
+	}
+
+	// TextFormatter is the default Formatter, e.g.:
+	//
+	//	ErrInternalServerError, Code=500, Msg=Internal server error, Login failed <= ErrUpdateOneFailed, db.UpdateOne failed, SaveUser failed, uid=123 <= mock mongodb error
+	//
+	// and with %+v:
+	//
+	//	ErrInternalServerError, Code=500, Msg=Internal server error, Login failed
+	//	    at github.com/ppc-games/ppcerrors_test.Login
+	//	        /Users/liangrui/Projects/go/ppcerrors/example_test.go:27
+	//	cause: ErrUpdateOneFailed, db.UpdateOne failed, SaveUser failed, uid=123
+	//	    at github.com/ppc-games/ppcerrors_test.SaveUser
+	//	        /Users/liangrui/Projects/go/ppcerrors/example_test.go:21
+	//	cause: mock mongodb error
+	//
+	// It uses Config.MessagesSeparator and Config.ErrorChainSeparator.
+	// When Config.RawPC is true, %+v prints "at 0x<PC>" instead of the function, file, and line of each layer,
+	// and a last line "build: <build ID> anchor: 0x<address>" for cmd/ppcerrsym.
+	// When Config.Timestamps is true, %+v prints the creation time of the innermost layer that has one,
+	// and for each outer layer, the time elapsed since then, see Timeline.
+	// When the chain has an instance ID, see InstanceID, it is appended as " (instance: <ID>)", and as a last line "instance: <ID>" with %+v.
+	// Errors not created by this package are printed by their own Error method, or their own Format method with %+v.
+	TextFormatter struct{}
+
+	// CompactFormatter renders error chains in a single line with the names of definitions and error codes
+	// but without their descriptions, e.g.:
+	//
+	//	ErrInternalServerError(500): Login failed <= ErrUpdateOneFailed: SaveUser failed uid=123 <= mock mongodb error
+	//
+	// and with %+v, the base file name and line number follow each layer:
+	//
+	//	ErrInternalServerError(500): Login failed [example_test.go:27] <= ErrUpdateOneFailed: SaveUser failed uid=123 [example_test.go:21] <= mock mongodb error
+	//
+	// When Config.RawPC is true, %+v prints "[0x<PC>]" instead of the file name and line number of each layer,
+	// and appends " (build: <build ID> anchor: 0x<address>)" for cmd/ppcerrsym.
+	// The instance ID of the chain, if any, is appended as " (instance: <ID>)".
+	CompactFormatter struct{}
+
+	// LogfmtFormatter renders error chains as logfmt key-value pairs prefixed by err.<index of the layer>, e.g.:
+	//
+	//	err.0.kind=errorCode err.0.name=ErrInternalServerError err.0.code=500 err.0.msg="Internal server error" err.0.message="Login failed" err.1.kind=foreign err.1.message="mock mongodb error"
+	//
+	// Fields are printed as err.<index>.field.<key>, and with %+v, err.<index>.func and err.<index>.file are added.
+	// When Config.RawPC is true, %+v prints err.<index>.pc instead of them, followed by err.build and err.anchor for cmd/ppcerrsym.
+	// The instance ID of the chain, if any, is printed last as err.instance.
+	LogfmtFormatter struct{}
+
+	// MultiLineFormatter renders each layer in its own line, even without %+v, e.g.:
+	//
+	//	ErrInternalServerError, Code=500, Msg=Internal server error, Login failed
+	//	cause: mock mongodb error
+	//
+	// The instance ID of the chain, if any, is printed in a last line "instance: <ID>".
+	// With %+v it prints the same as TextFormatter.
+	MultiLineFormatter struct{}
+)
+
+// WithFormatter attaches f to the outermost layer of err and returns the new error,
+// so that the chain is rendered by f instead of Config.Formatter, even after being wrapped again.
+// See WithFields for how the outermost layer is copied. WithFormatter returns nil when err is nil.
+func WithFormatter(err error, f Formatter) error {
+	if err == nil {
+		return nil
+	}
+	return amend(err, func(a *attrs) {
+		a.format = f
+	})
+}
+
+// Render renders err with f regardless of the formatter attached to err or Config.Formatter,
+// verbose selects Formatter.Verbose instead of Formatter.Text. Render returns "" when err is nil.
+func Render(err error, f Formatter, verbose bool) string {
+	if err == nil {
+		return ""
+	}
+	return render(f, chainOf(err, verbose), verbose)
+}
+
+// render renders layers with f, verbose selects Formatter.Verbose instead of Formatter.Text.
+func render(f Formatter, layers []Layer, verbose bool) string {
+	if verbose {
+		return f.Verbose(layers)
+	}
+	return f.Text(layers)
+}
+
+// chainOf returns the layers rendered by a Formatter for err, see Formatter.
+// The frames of the layers are only resolved when frames is true, e.g.: for Formatter.Verbose.
+func chainOf(err error, frames bool) []Layer {
+	build := unresolvedLayerOf
+	if frames {
+		build = layerOf
+	}
+
+	var layers []Layer
+	for err != nil {
+		e, ok := err.(*withCause)
+		if !ok {
+			layers = append(layers, build(err))
+			break
+		}
+		layers = append(layers, build(e.error))
+		err = e.cause
+	}
+	return layers
+}
+
+// formatterOf returns the formatter attached to the outermost layer of err that has one by WithFormatter,
+// or Config.Formatter, or TextFormatter when Config.Formatter is nil.
+func formatterOf(err error) Formatter {
+	for err != nil {
+		layer := err
+		e, isCause := err.(*withCause)
+		if isCause {
+			layer = e.error
+		}
+		if f, ok := layer.(interface{ formatter() Formatter }); ok && f.formatter() != nil {
+			return f.formatter()
+		}
+		if !isCause {
+			break
+		}
+		err = e.cause
+	}
+
+	if Config.Formatter != nil {
+		return Config.Formatter
+	}
+	return TextFormatter{}
+}
+
+// formatChain renders the chain of err with its formatter according to the given format specifier.
+// It is shared by the fmt.Formatter implementations of the errors created by this package.
+func formatChain(err error, s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			_, _ = io.WriteString(s, formatterOf(err).Verbose(chainOf(err, true)))
+			return
+		}
+		fallthrough
+	case 's', 'q':
+		_, _ = io.WriteString(s, err.Error())
+	}
+}
+
+// isBuiltin reports whether err is a layer created by this package.
+func isBuiltin(err error) bool {
+	switch err.(type) {
+	case *withMessage, *withDefinition, *withErrorCode:
+		return true
+	}
+	return false
+}
+
+// isEmpty reports whether l carries nothing to print, e.g.: the layer added by WithSeverity to a foreign error,
+// unlike the layers created by Wrap with an empty message, which are printed as they are.
+func isEmpty(l Layer) bool {
+	a, ok := l.Err.(interface{ isAnonymous() bool })
+	return l.Kind == MessageLayer && l.Message == "" && len(l.Fields) == 0 && ok && a.isAnonymous()
+}
+
+// instanceOf returns the instance ID of the innermost layer in layers that has one, see InstanceID.
+func instanceOf(layers []Layer) string {
+	for i := len(layers) - 1; i >= 0; i-- {
+		if layers[i].InstanceID != "" {
+			return layers[i].InstanceID
+		}
+	}
+	return ""
+}
+
+// writeInstance appends the instance ID id between prefix and suffix to b, unless id is "" or b already ends with it,
+// e.g.: when the last layer is a foreign error wrapping an error of this package, whose own text already prints the ID.
+func writeInstance(b *strings.Builder, id string, prefix string, suffix string) {
+	if text := prefix + id + suffix; id != "" && !strings.HasSuffix(b.String(), text) {
+		b.WriteString(text)
+	}
+}
+
+// Timeline returns the index of the innermost layer in layers that has a creation time, and its creation time,
+// the elapsed time of an outer layer is its creation time minus the returned one. Layers only have a creation time
+// when Config.Timestamps is true. Timeline returns -1 and the zero time when no layer has one.
+func Timeline(layers []Layer) (int, time.Time) {
+	for i := len(layers) - 1; i >= 0; i-- {
+		if !layers[i].Time.IsZero() {
+			return i, layers[i].Time
+		}
+	}
+	return -1, time.Time{}
+}
+
+// layerText renders a single layer in the layout of TextFormatter.
+func layerText(l Layer) string {
+	if !isBuiltin(l.Err) {
+		return l.Err.Error()
+	}
+
+	var b strings.Builder
+	switch l.Kind {
+	case ErrorCodeLayer:
+		b.WriteString(errorCodeName(l.ErrorCode))
+		b.WriteString(", Code=")
+		b.WriteString(strconv.Itoa(l.ErrorCode.Code()))
+		b.WriteString(", Msg=")
+		b.WriteString(l.ErrorCode.Msg())
+	case DefinitionLayer:
+		b.WriteString(definitionName(l.Definition))
+		b.WriteString(Config.MessagesSeparator)
+		b.WriteString(l.Definition.Desc())
+	}
+
+	if l.Message != "" {
+		if b.Len() > 0 {
+			b.WriteString(Config.MessagesSeparator)
+		}
+		b.WriteString(l.Message)
+	}
+	writeFields(&b, l.Fields)
+
+	return b.String()
+}
+
+// Text implements Formatter.
+func (TextFormatter) Text(layers []Layer) string {
+	var b strings.Builder
+	n := 0
+	for _, l := range layers {
+		if isEmpty(l) {
+			continue
+		}
+		if n++; n > 1 {
+			b.WriteString(Config.ErrorChainSeparator)
+		}
+		b.WriteString(layerText(l))
+	}
+	writeInstance(&b, instanceOf(layers), " (instance: ", ")")
+	return b.String()
+}
+
+// Verbose implements Formatter.
+func (TextFormatter) Verbose(layers []Layer) string {
+	var b strings.Builder
+	base, start := Timeline(layers)
+	n := 0
+	for i, l := range layers {
+		if isEmpty(l) && l.Frame.PC == 0 {
+			continue
+		}
+		if n++; n > 1 {
+			b.WriteString("\ncause: ")
+		}
+
+		if !isBuiltin(l.Err) {
+			_, _ = fmt.Fprintf(&b, "%+v", l.Err)
+			continue
+		}
+
+		b.WriteString(layerText(l))
+		switch {
+		case l.Frame.PC != 0 && Config.RawPC:
+			_, _ = fmt.Fprintf(&b, "\n    at %#x", l.Frame.PC)
+		case l.Frame.PC != 0:
+			// Note: This uses the Frame from the github.com/pkg/errors library to format the output,
+			// see formatWithPC for the formatting verbs.
+			_, _ = fmt.Fprintf(&b, "\n    at %+v", errors.Frame(l.Frame.PC))
+		}
+		switch {
+		case i == base:
+			b.WriteString("\n    time: ")
+			b.WriteString(start.Format(time.RFC3339Nano))
+		case !l.Time.IsZero():
+			b.WriteString("\n    elapsed: +")
+			b.WriteString(l.Time.Sub(start).String())
+		}
+	}
+	if Config.RawPC {
+		b.WriteString("\n")
+		b.WriteString(rawPCTrailer())
+	}
+	writeInstance(&b, instanceOf(layers), "\ninstance: ", "")
+	return b.String()
+}
+
+// compactText renders a single layer in the layout of CompactFormatter.
+func compactText(l Layer) string {
+	var b strings.Builder
+	switch l.Kind {
+	case ErrorCodeLayer:
+		b.WriteString(errorCodeName(l.ErrorCode))
+		b.WriteString("(")
+		b.WriteString(strconv.Itoa(l.ErrorCode.Code()))
+		b.WriteString(")")
+	case DefinitionLayer:
+		b.WriteString(definitionName(l.Definition))
+	case ForeignLayer:
+		return l.Err.Error()
+	}
+
+	if l.Message != "" {
+		if b.Len() > 0 {
+			b.WriteString(": ")
+		}
+		b.WriteString(l.Message)
+	}
+	for _, f := range l.Fields {
+		if b.Len() > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(f.String())
+	}
+
+	return b.String()
+}
+
+// Text implements Formatter.
+func (CompactFormatter) Text(layers []Layer) string {
+	return CompactFormatter{}.render(layers, false)
+}
+
+// Verbose implements Formatter.
+func (CompactFormatter) Verbose(layers []Layer) string {
+	return CompactFormatter{}.render(layers, true)
+}
+
+func (CompactFormatter) render(layers []Layer, verbose bool) string {
+	var b strings.Builder
+	n := 0
+	for _, l := range layers {
+		if isEmpty(l) {
+			continue
+		}
+		if n++; n > 1 {
+			b.WriteString(Config.ErrorChainSeparator)
+		}
+		b.WriteString(compactText(l))
+		switch {
+		case verbose && l.Frame.PC != 0 && Config.RawPC:
+			_, _ = fmt.Fprintf(&b, " [%#x]", l.Frame.PC)
+		case verbose && l.Frame.PC != 0:
+			_, _ = fmt.Fprintf(&b, " [%s:%d]", filepath.Base(l.Frame.File), l.Frame.Line)
+		}
+	}
+	if verbose && Config.RawPC {
+		b.WriteString(" (")
+		b.WriteString(rawPCTrailer())
+		b.WriteString(")")
+	}
+	writeInstance(&b, instanceOf(layers), " (instance: ", ")")
+	return b.String()
+}
+
+// Text implements Formatter.
+func (LogfmtFormatter) Text(layers []Layer) string {
+	return LogfmtFormatter{}.render(layers, false)
+}
+
+// Verbose implements Formatter.
+func (LogfmtFormatter) Verbose(layers []Layer) string {
+	return LogfmtFormatter{}.render(layers, true)
+}
+
+func (LogfmtFormatter) render(layers []Layer, verbose bool) string {
+	var b strings.Builder
+	pair := func(prefix string, key string, value string) {
+		if b.Len() > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(prefix)
+		b.WriteString(key)
+		b.WriteString("=")
+		b.WriteString(logfmt.Value(value))
+	}
+
+	i := 0
+	for _, l := range layers {
+		if isEmpty(l) {
+			continue
+		}
+		prefix := "err." + strconv.Itoa(i) + "."
+		i++
+
+		pair(prefix, "kind", l.Kind.String())
+		switch l.Kind {
+		case ErrorCodeLayer:
+			pair(prefix, "name", errorCodeName(l.ErrorCode))
+			pair(prefix, "code", strconv.Itoa(l.ErrorCode.Code()))
+			pair(prefix, "msg", l.ErrorCode.Msg())
+		case DefinitionLayer:
+			pair(prefix, "name", definitionName(l.Definition))
+			pair(prefix, "desc", l.Definition.Desc())
+		}
+		if l.Message != "" {
+			pair(prefix, "message", l.Message)
+		}
+		for _, f := range l.Fields {
+			f = Redact(f)
+			pair(prefix, "field."+f.Key, fmt.Sprint(f.Value))
+		}
+		switch {
+		case verbose && l.Frame.PC != 0 && Config.RawPC:
+			pair(prefix, "pc", fmt.Sprintf("%#x", l.Frame.PC))
+		case verbose && l.Frame.PC != 0:
+			pair(prefix, "func", l.Frame.Function)
+			pair(prefix, "file", l.Frame.File+":"+strconv.Itoa(l.Frame.Line))
+		}
+	}
+	if verbose && Config.RawPC {
+		pair("err.", "build", BuildID())
+		pair("err.", "anchor", fmt.Sprintf("%#x", rawPCAnchorAddr()))
+	}
+	if id := instanceOf(layers); id != "" {
+		pair("err.", "instance", id)
+	}
+	return b.String()
+}
+
+// Text implements Formatter.
+func (MultiLineFormatter) Text(layers []Layer) string {
+	var b strings.Builder
+	n := 0
+	for _, l := range layers {
+		if isEmpty(l) {
+			continue
+		}
+		if n++; n > 1 {
+			b.WriteString("\ncause: ")
+		}
+		b.WriteString(layerText(l))
+	}
+	writeInstance(&b, instanceOf(layers), "\ninstance: ", "")
+	return b.String()
+}
+
+// Verbose implements Formatter.
+func (MultiLineFormatter) Verbose(layers []Layer) string {
+	return TextFormatter{}.Verbose(layers)
+}
@@ -0,0 +1,174 @@
+package ppcerrors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatters(t *testing.T) {
+	errUpdateOneFailed := NewDefinition("ErrUpdateOneFailed", "db.UpdateOne failed")
+	errInternalServerError := NewErrorCode("ErrInternalServerError", 500, "Internal server error")
+	err := errInternalServerError.Wrap(WithFields(errUpdateOneFailed.Wrap(errors.New("mock mongodb error"), "SaveUser failed"), F("uid", 123)), "Login failed")
+
+	tests := []struct {
+		name      string
+		formatter Formatter
+		expected  string
+	}{
+		{
+			"TextFormatter",
+			TextFormatter{},
+			"ErrInternalServerError, Code=500, Msg=Internal server error, Login failed <= ErrUpdateOneFailed, db.UpdateOne failed, SaveUser failed, uid=123 <= mock mongodb error",
+		},
+		{
+			"CompactFormatter",
+			CompactFormatter{},
+			"ErrInternalServerError(500): Login failed <= ErrUpdateOneFailed: SaveUser failed uid=123 <= mock mongodb error",
+		},
+		{
+			"LogfmtFormatter",
+			LogfmtFormatter{},
+			`err.0.kind=errorCode err.0.name=ErrInternalServerError err.0.code=500 err.0.msg="Internal server error" err.0.message="Login failed" ` +
+				`err.1.kind=definition err.1.name=ErrUpdateOneFailed err.1.desc="db.UpdateOne failed" err.1.message="SaveUser failed" err.1.field.uid=123 ` +
+				`err.2.kind=foreign err.2.message="mock mongodb error"`,
+		},
+		{
+			"MultiLineFormatter",
+			MultiLineFormatter{},
+			"ErrInternalServerError, Code=500, Msg=Internal server error, Login failed\ncause: ErrUpdateOneFailed, db.UpdateOne failed, SaveUser failed, uid=123\ncause: mock mongodb error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := Render(err, tt.formatter, false); actual != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFormattersVerbose(t *testing.T) {
+	Config.Caller = true
+	defer func() { Config.Caller = false }()
+
+	err := Wrap(errors.New("root"), "wrapped")
+
+	t.Run("CompactFormatter", func(t *testing.T) {
+		actual := Render(err, CompactFormatter{}, true)
+		if !strings.HasPrefix(actual, "wrapped [formatter_test.go:") || !strings.HasSuffix(actual, "] <= root") {
+			t.Errorf("Expected the base file name and line number, got '%s'", actual)
+		}
+	})
+
+	t.Run("LogfmtFormatter", func(t *testing.T) {
+		actual := Render(err, LogfmtFormatter{}, true)
+		if !strings.Contains(actual, "err.0.func=github.com/ppc-games/ppcerrors.TestFormattersVerbose err.0.file=") {
+			t.Errorf("Expected the function and file, got '%s'", actual)
+		}
+	})
+
+	t.Run("MultiLineFormatter", func(t *testing.T) {
+		if Render(err, MultiLineFormatter{}, true) != fmt.Sprintf("%+v", err) {
+			t.Error("Expected MultiLineFormatter to print the same as TextFormatter in verbose mode")
+		}
+	})
+
+	t.Run("Only resolve frames in verbose mode", func(t *testing.T) {
+		if frame := chainOf(err, false)[0].Frame; frame.PC == 0 || frame.Function != "" {
+			t.Errorf("Expected only the PC for Text, got %+v", frame)
+		}
+		if frame := chainOf(err, true)[0].Frame; frame.Function != "github.com/ppc-games/ppcerrors.TestFormattersVerbose" {
+			t.Errorf("Expected the resolved frame for Verbose, got %+v", frame)
+		}
+	})
+}
+
+func TestFormatterSelection(t *testing.T) {
+	err := Wrap(errors.New("root"), "wrapped")
+
+	t.Run("Global formatter", func(t *testing.T) {
+		Config.Formatter = CompactFormatter{}
+		defer func() { Config.Formatter = TextFormatter{} }()
+
+		if actual := Wrap(err, "outer").Error(); actual != "outer <= wrapped <= root" {
+			t.Errorf("Expected 'outer <= wrapped <= root', got '%s'", actual)
+		}
+	})
+
+	t.Run("Formatter attached to the error", func(t *testing.T) {
+		formatted := Wrap(WithFormatter(err, MultiLineFormatter{}), "outer")
+		if actual := formatted.Error(); actual != "outer\ncause: wrapped\ncause: root" {
+			t.Errorf("Expected the attached formatter to be used, got '%s'", actual)
+		}
+		if err.Error() != "wrapped <= root" {
+			t.Error("Expected the original error to keep the global formatter")
+		}
+	})
+
+	t.Run("Skip empty layers", func(t *testing.T) {
+		if actual := WithSeverity(errors.New("root"), SeverityWarn).Error(); actual != "root" {
+			t.Errorf("Expected 'root', got '%s'", actual)
+		}
+	})
+
+	t.Run("Keep layers wrapped with an empty message", func(t *testing.T) {
+		err := Wrap(errors.New("root"), "")
+		if actual := err.Error(); actual != " <= root" {
+			t.Errorf("Expected ' <= root', got '%s'", actual)
+		}
+		if actual := fmt.Sprintf("%+v", err); actual != "\ncause: root" {
+			t.Errorf("Expected '\\ncause: root', got '%s'", actual)
+		}
+	})
+
+	t.Run("Nil error", func(t *testing.T) {
+		if Render(nil, TextFormatter{}, true) != "" {
+			t.Error("Expected an empty string")
+		}
+	})
+}
+
+func TestTimeline(t *testing.T) {
+	errUpdateOneFailed := NewDefinition("ErrUpdateOneFailed", "db.UpdateOne failed")
+	errInternalServerError := NewErrorCode("ErrInternalServerError", 500, "Internal server error")
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		err := errInternalServerError.Wrap(errUpdateOneFailed.New(), "Login failed")
+		if i, _ := Timeline(chainOf(err, true)); i != -1 {
+			t.Errorf("Expected no layer to have a creation time, got %d", i)
+		}
+		if actual := fmt.Sprintf("%+v", err); strings.Contains(actual, "time: ") || strings.Contains(actual, "elapsed: ") {
+			t.Errorf("Expected no time in the verbose output, got '%s'", actual)
+		}
+	})
+
+	Config.Timestamps = true
+	defer func() { Config.Timestamps = false }()
+
+	inner := errUpdateOneFailed.Wrap(errors.New("mock mongodb error"), "SaveUser failed")
+	time.Sleep(time.Millisecond)
+	err := errInternalServerError.Wrap(inner, "Login failed")
+
+	t.Run("The innermost timed layer is the base", func(t *testing.T) {
+		layers := chainOf(err, true)
+		i, start := Timeline(layers)
+		if i != 1 || !start.Equal(layers[1].Time) {
+			t.Errorf("Expected the base to be the layer 1, got %d", i)
+		}
+		if elapsed := layers[0].Time.Sub(start); elapsed < time.Millisecond {
+			t.Errorf("Expected at least 1ms elapsed, got %s", elapsed)
+		}
+	})
+
+	t.Run("Print the time of the base and the elapsed time of outer layers", func(t *testing.T) {
+		actual := fmt.Sprintf("%+v", err)
+		lines := strings.Split(actual, "\n")
+		if len(lines) != 5 || !strings.HasPrefix(lines[1], "    elapsed: +") || !strings.HasPrefix(lines[3], "    time: ") {
+			t.Errorf("Expected the elapsed time and the time in the verbose output, got '%s'", actual)
+		}
+	})
+}
@@ -1,5 +1,5 @@
 module github.com/ppc-games/ppcerrors
 
-go 1.16
+go 1.23
 
 require github.com/pkg/errors v0.9.1
@@ -0,0 +1,102 @@
+package ppcerrors
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// Event describes an error created by this package, it is passed to the hooks registered by OnCreate.
+	Event struct {
+		// Err is the new error, as returned to the caller of the constructor.
+		Err error
+		// Layer is the layer added by the constructor, Layer.Definition and Layer.ErrorCode tell which definition
+		// or error code it was created from, and Layer.Frame where it was created when Config.Caller is true.
+		Layer Layer
+		// Cause is the error wrapped by the new error, nil for the errors created by New.
+		Cause error
+	}
+
+	// createHook is a hook registered by OnCreate, it is compared by address to remove it.
+	createHook struct {
+		fn func(e Event)
+	}
+)
+
+var (
+	createHooksMu sync.Mutex
+	// createHooks is nil when no hook is registered, so that creating errors only costs an atomic load.
+	createHooks atomic.Pointer[[]*createHook]
+)
+
+// OnCreate registers fn to be called each time an error is created by New, Wrap, and their variants,
+// of definitions, error codes, and this package, e.g.: to count errors by definition, add them to the current trace span, or sample them.
+// Hooks are called synchronously by the goroutine creating the error, in the order of registration,
+// and a panic in a hook is recovered so that it affects neither the other hooks nor the caller of the constructor.
+// It returns a function removing the hook, which can be called several times.
+//
+// Note: Errors copied by WithFields, WithSeverity, and the other functions amending an error are not reported as created.
+func OnCreate(fn func(e Event)) (remove func()) {
+	h := &createHook{fn: fn}
+
+	createHooksMu.Lock()
+	defer createHooksMu.Unlock()
+	var hooks []*createHook
+	if current := createHooks.Load(); current != nil {
+		hooks = append(hooks, *current...)
+	}
+	hooks = append(hooks, h)
+	createHooks.Store(&hooks)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { removeCreateHook(h) })
+	}
+}
+
+// removeCreateHook unregisters h.
+func removeCreateHook(h *createHook) {
+	createHooksMu.Lock()
+	defer createHooksMu.Unlock()
+	current := createHooks.Load()
+	if current == nil {
+		return
+	}
+	var hooks []*createHook
+	for _, other := range *current {
+		if other != h {
+			hooks = append(hooks, other)
+		}
+	}
+	if len(hooks) == 0 {
+		createHooks.Store(nil)
+		return
+	}
+	createHooks.Store(&hooks)
+}
+
+// created calls the hooks registered by OnCreate for the new error err wrapping cause, and returns err.
+func created(err error, cause error) error {
+	hooks := createHooks.Load()
+	if hooks == nil {
+		return err
+	}
+
+	layer := err
+	if e, ok := err.(*withCause); ok {
+		layer = e.error
+	}
+	event := Event{Err: err, Layer: layerOf(layer), Cause: cause}
+	for _, h := range *hooks {
+		callHook(h, event)
+	}
+	return err
+}
+
+// callHook calls h with e, recovering from a panic in h.
+func callHook(h *createHook, e Event) {
+	defer func() {
+		_ = recover()
+	}()
+	h.fn(e)
+}
@@ -0,0 +1,76 @@
+package ppcerrors
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOnCreate(t *testing.T) {
+	errUpdateOneFailed := NewDefinition("ErrUpdateOneFailed", "db.UpdateOne failed")
+	errInternalServerError := NewErrorCode("ErrInternalServerError", 500, "Internal server error")
+
+	t.Run("Describe the new layer", func(t *testing.T) {
+		var events []Event
+		remove := OnCreate(func(e Event) { events = append(events, e) })
+		defer remove()
+
+		root := errors.New("mock mongodb error")
+		inner := errUpdateOneFailed.Wrap(root, "SaveUser failed")
+		outer := errInternalServerError.Wrap(inner, "Login failed")
+		_ = Wrap(nil, "not created")
+
+		if len(events) != 2 {
+			t.Fatalf("Expected 2 events, got %d", len(events))
+		}
+		if events[0].Err != inner || events[0].Cause != root || events[0].Layer.Definition != errUpdateOneFailed || events[0].Layer.Message != "SaveUser failed" {
+			t.Errorf("Expected the event of ErrUpdateOneFailed, got %+v", events[0])
+		}
+		if events[1].Err != outer || events[1].Cause != inner || events[1].Layer.ErrorCode != errInternalServerError {
+			t.Errorf("Expected the event of ErrInternalServerError, got %+v", events[1])
+		}
+	})
+
+	t.Run("Record the caller", func(t *testing.T) {
+		Config.Caller = true
+		defer func() { Config.Caller = false }()
+
+		var event Event
+		remove := OnCreate(func(e Event) { event = e })
+		defer remove()
+
+		_ = errUpdateOneFailed.New()
+		if event.Layer.Frame.Function != "github.com/ppc-games/ppcerrors.TestOnCreate.func2" {
+			t.Errorf("Expected the caller to be recorded, got '%s'", event.Layer.Frame.Function)
+		}
+	})
+
+	t.Run("Call hooks in order and isolate panics", func(t *testing.T) {
+		var calls []string
+		removeFirst := OnCreate(func(e Event) { calls = append(calls, "first") })
+		removePanic := OnCreate(func(e Event) { panic("hook failed") })
+		removeLast := OnCreate(func(e Event) { calls = append(calls, "last") })
+		defer removePanic()
+		defer removeLast()
+
+		if err := errUpdateOneFailed.New(); err == nil {
+			t.Fatal("Expected the error to be created")
+		}
+		if len(calls) != 2 || calls[0] != "first" || calls[1] != "last" {
+			t.Errorf("Expected [first last], got %v", calls)
+		}
+
+		removeFirst()
+		removeFirst()
+		calls = nil
+		_ = errUpdateOneFailed.New()
+		if len(calls) != 1 || calls[0] != "last" {
+			t.Errorf("Expected [last], got %v", calls)
+		}
+	})
+
+	t.Run("No hook after removal", func(t *testing.T) {
+		if createHooks.Load() != nil {
+			t.Error("Expected the hooks to be cleared")
+		}
+	})
+}
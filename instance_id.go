@@ -0,0 +1,76 @@
+package ppcerrors
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// instanceSeq distinguishes the IDs generated by TimeOrderedID within the same millisecond.
+var instanceSeq atomic.Uint32
+
+// TimeOrderedID generates a 24-character hexadecimal ID made of the current Unix time in milliseconds,
+// a sequence number, and random bytes, so that IDs sort by creation time, e.g.: 019a0f5c3e2b00a7c41f9d3e.
+// It is meant to be used as Config.InstanceID.
+func TimeOrderedID() string {
+	var b [12]byte
+	ms := uint64(time.Now().UnixMilli())
+	for i := 5; i >= 0; i-- {
+		b[i] = byte(ms)
+		ms >>= 8
+	}
+	seq := instanceSeq.Add(1)
+	b[6] = byte(seq >> 8)
+	b[7] = byte(seq)
+	if _, err := rand.Read(b[8:]); err != nil {
+		s := strconv.FormatUint(uint64(time.Now().UnixNano()), 16)
+		copy(b[8:], s[len(s)-4:])
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// newInstanceID returns the instance ID of a layer being created with the given cause:
+// the instance ID of cause when it has one, so that the innermost ID is propagated outward,
+// otherwise a new one generated by Config.InstanceID. It returns "" when Config.InstanceID is nil.
+func newInstanceID(cause error) string {
+	if Config.InstanceID == nil {
+		return ""
+	}
+	id, ok := outermostInstanceID(cause)
+	if !ok {
+		id = InstanceID(cause)
+	}
+	if id != "" {
+		return id
+	}
+	return Config.InstanceID()
+}
+
+// outermostInstanceID returns the instance ID of the outermost layer of err, which already carries the ID propagated
+// from its causes, so that wrapping does not walk the whole chain. It returns false when the outermost layer
+// has no InstanceID method, e.g.: an error wrapped by fmt.Errorf, whose causes must be walked instead.
+func outermostInstanceID(err error) (string, bool) {
+	if e, ok := err.(*withCause); ok {
+		err = e.error
+	}
+	if i, ok := err.(interface{ InstanceID() string }); ok {
+		return i.InstanceID(), true
+	}
+	return "", false
+}
+
+// InstanceID returns the instance ID of the innermost layer in err's chain that has one,
+// the ID identifies the occurrence of err, so that the error shown by a client can be found in the server logs.
+// Instance IDs are only generated when Config.InstanceID is set. InstanceID returns "" when err has no instance ID.
+func InstanceID(err error) string {
+	id := ""
+	walkErrors(err, func(e error) bool {
+		if i, ok := e.(interface{ InstanceID() string }); ok && i.InstanceID() != "" {
+			id = i.InstanceID()
+		}
+		return true
+	})
+	return id
+}
@@ -0,0 +1,107 @@
+package ppcerrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestInstanceID(t *testing.T) {
+	errUpdateOneFailed := NewDefinition("ErrUpdateOneFailed", "db.UpdateOne failed")
+	errInternalServerError := NewErrorCode("ErrInternalServerError", 500, "Internal server error")
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		if id := InstanceID(errUpdateOneFailed.New()); id != "" {
+			t.Errorf("Expected no instance ID, got '%s'", id)
+		}
+	})
+
+	n := 0
+	Config.InstanceID = func() string {
+		n++
+		return fmt.Sprintf("id%d", n)
+	}
+	defer func() { Config.InstanceID = nil }()
+
+	t.Run("Propagate the innermost ID outward", func(t *testing.T) {
+		inner := errUpdateOneFailed.Wrap(errors.New("mock mongodb error"), "SaveUser failed")
+		id := InstanceID(inner)
+		if id == "" {
+			t.Fatal("Expected an instance ID")
+		}
+		err := errInternalServerError.Wrap(Wrap(inner, "retrying"), "Login failed")
+		if actual := InstanceID(err); actual != id {
+			t.Errorf("Expected '%s', got '%s'", id, actual)
+		}
+		for l := range Layers(err) {
+			if l.Kind != ForeignLayer && l.InstanceID != id {
+				t.Errorf("Expected layer %s to have the instance ID '%s', got '%s'", l.Kind, id, l.InstanceID)
+			}
+		}
+	})
+
+	t.Run("Propagate the ID through foreign wrappers", func(t *testing.T) {
+		inner := errUpdateOneFailed.New()
+		err := Wrap(fmt.Errorf("retrying: %w", inner), "Login failed")
+		if id := InstanceID(inner); id == "" || InstanceID(err) != id {
+			t.Errorf("Expected '%s', got '%s'", id, InstanceID(err))
+		}
+	})
+
+	t.Run("Include the ID in Error and %+v", func(t *testing.T) {
+		err := errUpdateOneFailed.New("SaveUser failed")
+		id := InstanceID(err)
+		expected := "ErrUpdateOneFailed, db.UpdateOne failed, SaveUser failed (instance: " + id + ")"
+		if actual := err.Error(); actual != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, actual)
+		}
+		if actual := fmt.Sprintf("%+v", err); !strings.HasSuffix(actual, "\ninstance: "+id) {
+			t.Errorf("Expected the verbose output to end with the instance ID, got '%s'", actual)
+		}
+		expected = "err.0.kind=definition err.0.name=ErrUpdateOneFailed err.0.desc=\"db.UpdateOne failed\" err.0.message=\"SaveUser failed\" err.instance=" + id
+		if actual := Render(err, LogfmtFormatter{}, false); actual != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, actual)
+		}
+	})
+
+	t.Run("Print the ID once through foreign wrappers", func(t *testing.T) {
+		err := Wrap(fmt.Errorf("mid: %w", Wrap(errors.New("root"), "inner")), "outer")
+		id := InstanceID(err)
+		expected := "outer <= mid: inner <= root (instance: " + id + ")"
+		if actual := err.Error(); actual != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, actual)
+		}
+		if layers := ParseText(err.Error()); layers[len(layers)-1].Message != "root" {
+			t.Errorf("Expected the ID to be stripped from the parsed message, got '%s'", layers[len(layers)-1].Message)
+		}
+	})
+
+	t.Run("Include the ID in JSON", func(t *testing.T) {
+		err := errUpdateOneFailed.New()
+		data, _ := json.Marshal(err)
+		var decoded struct {
+			Instance string `json:"instance"`
+		}
+		if e := json.Unmarshal(data, &decoded); e != nil || decoded.Instance != InstanceID(err) {
+			t.Errorf("Expected the instance '%s', got '%s'", InstanceID(err), data)
+		}
+	})
+
+	t.Run("Nil error", func(t *testing.T) {
+		if id := InstanceID(nil); id != "" {
+			t.Errorf("Expected no instance ID, got '%s'", id)
+		}
+	})
+}
+
+func TestTimeOrderedID(t *testing.T) {
+	a, b := TimeOrderedID(), TimeOrderedID()
+	if len(a) != 24 || len(b) != 24 {
+		t.Errorf("Expected 24-character IDs, got '%s' and '%s'", a, b)
+	}
+	if a >= b {
+		t.Errorf("Expected '%s' to sort before '%s'", a, b)
+	}
+}
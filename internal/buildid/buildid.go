@@ -0,0 +1,44 @@
+// Package buildid reads the Go build ID of ELF binaries, which is kept by -s -w,
+// so that a stripped binary and the unstripped one it was built with can be matched.
+package buildid
+
+import (
+	"bytes"
+	"debug/elf"
+	"errors"
+	"fmt"
+)
+
+// Read returns the Go build ID stored in the .note.go.buildid section of the ELF binary at path.
+func Read(path string) (string, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	return FromELF(f)
+}
+
+// FromELF returns the Go build ID stored in the .note.go.buildid section of f.
+func FromELF(f *elf.File) (string, error) {
+	s := f.Section(".note.go.buildid")
+	if s == nil {
+		return "", errors.New("buildid: no .note.go.buildid section")
+	}
+	data, err := s.Data()
+	if err != nil {
+		return "", err
+	}
+
+	// The note is laid out as namesz, descsz, type, the name "Go\x00\x00", and the build ID.
+	if len(data) < 16 {
+		return "", fmt.Errorf("buildid: note too short: %d bytes", len(data))
+	}
+	namesz := f.ByteOrder.Uint32(data[0:4])
+	descsz := f.ByteOrder.Uint32(data[4:8])
+	if namesz != 4 || !bytes.Equal(data[12:16], []byte("Go\x00\x00")) || uint64(len(data)) < 16+uint64(descsz) {
+		return "", errors.New("buildid: malformed note")
+	}
+	return string(data[16 : 16+descsz]), nil
+}
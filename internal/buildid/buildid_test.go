@@ -0,0 +1,30 @@
+package buildid
+
+import (
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Only ELF binaries are supported")
+	}
+
+	t.Run("Read the build ID of the test binary", func(t *testing.T) {
+		path, err := os.Executable()
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := Read(path)
+		if err != nil || id == "" {
+			t.Errorf("Expected a build ID, got '%s' and '%v'", id, err)
+		}
+	})
+
+	t.Run("Not an ELF binary", func(t *testing.T) {
+		if _, err := Read("buildid.go"); err == nil {
+			t.Error("Expected an error")
+		}
+	})
+}
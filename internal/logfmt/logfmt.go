@@ -0,0 +1,16 @@
+// Package logfmt quotes the values printed by ppcerrors.LogfmtFormatter, so that cmd/ppcerrsym,
+// which rewrites some of them, quotes them the same way.
+package logfmt
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Value returns v quoted when it is empty or contains spaces, quotes, equal signs, or control characters, otherwise v itself.
+func Value(v string) string {
+	if v == "" || strings.ContainsAny(v, " =\"\t\r\n") || strconv.Quote(v) != `"`+v+`"` {
+		return strconv.Quote(v)
+	}
+	return v
+}
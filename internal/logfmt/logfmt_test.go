@@ -0,0 +1,24 @@
+package logfmt
+
+import "testing"
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"Plain value", "ErrUpdateOneFailed", "ErrUpdateOneFailed"},
+		{"Empty value", "", `""`},
+		{"Spaces", "db.UpdateOne failed", `"db.UpdateOne failed"`},
+		{"Equal signs and quotes", `a="b"`, `"a=\"b\""`},
+		{"Control characters", "a\x00b", `"a\x00b"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := Value(tt.value); actual != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, actual)
+			}
+		})
+	}
+}
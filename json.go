@@ -0,0 +1,158 @@
+package ppcerrors
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type (
+	// jsonError is the JSON representation of an error chain.
+	jsonError struct {
+		Error      string          `json:"error"`
+		Instance   string          `json:"instance,omitempty"`
+		BuildID    string          `json:"build_id,omitempty"`
+		Anchor     string          `json:"anchor,omitempty"`
+		Layers     []jsonLayer     `json:"layers"`
+		Violations []jsonViolation `json:"violations,omitempty"`
+	}
+
+	// jsonViolation is the JSON representation of a Violation.
+	jsonViolation struct {
+		Path    string `json:"path"`
+		Name    string `json:"name,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+
+	// jsonLayer is the JSON representation of a Layer.
+	jsonLayer struct {
+		Kind    string                 `json:"kind"`
+		Name    string                 `json:"name,omitempty"`
+		Desc    string                 `json:"desc,omitempty"`
+		Code    int                    `json:"code,omitempty"`
+		Msg     string                 `json:"msg,omitempty"`
+		Message string                 `json:"message,omitempty"`
+		Public  string                 `json:"public,omitempty"`
+		Fields  map[string]interface{} `json:"fields,omitempty"`
+		Detail  interface{}            `json:"detail,omitempty"`
+		Frame   *jsonFrame             `json:"frame,omitempty"`
+		Time    *time.Time             `json:"time,omitempty"`
+		Elapsed string                 `json:"elapsed,omitempty"`
+	}
+
+	// jsonFrame is the JSON representation of a Frame.
+	jsonFrame struct {
+		PC       string `json:"pc,omitempty"`
+		Function string `json:"function,omitempty"`
+		File     string `json:"file,omitempty"`
+		Line     int    `json:"line,omitempty"`
+	}
+)
+
+// MarshalJSON encodes err and its error chain as JSON, e.g.:
+//
+//	{"error":"ErrInternalServerError, Code=500, Msg=Internal server error, Login failed <= mock mongodb error",
+//	 "layers":[{"kind":"errorCode","name":"ErrInternalServerError","code":500,"msg":"Internal server error","message":"Login failed"},
+//	           {"kind":"foreign","message":"mock mongodb error"}]}
+//
+// The instance ID of err, if any, is encoded as "instance", see InstanceID.
+// When Config.Timestamps is true, the innermost layer that has a creation time encodes it as "time",
+// and the outer layers encode the time elapsed since then as "elapsed", e.g.: "1.5ms", see Timeline.
+// When Config.RawPC is true, frames only encode "pc", and "build_id" and "anchor" are added for cmd/ppcerrsym.
+// The violations of an error returned by ValidationErrors.Err are also listed as "violations", with their paths,
+// the names of their definitions, and their messages.
+//
+// The errors created by this package implement json.Marshaler by calling MarshalJSON,
+// so they can be passed to json.Marshal directly. MarshalJSON encodes null when err is nil.
+func MarshalJSON(err error) ([]byte, error) {
+	if err == nil {
+		return []byte("null"), nil
+	}
+
+	var layers []Layer
+	Walk(err, func(l Layer) bool {
+		layers = append(layers, l)
+		return true
+	})
+
+	je := jsonError{Error: err.Error(), Instance: InstanceID(err)}
+	if Config.RawPC {
+		je.BuildID = BuildID()
+		je.Anchor = fmt.Sprintf("%#x", rawPCAnchorAddr())
+	}
+	base, start := Timeline(layers)
+	for i, l := range layers {
+		jl := jsonLayerOf(l)
+		switch {
+		case i == base:
+			jl.Time = &start
+		case !l.Time.IsZero():
+			jl.Elapsed = l.Time.Sub(start).String()
+		}
+		je.Layers = append(je.Layers, jl)
+	}
+	for _, v := range Violations(err) {
+		je.Violations = append(je.Violations, jsonViolation{Path: v.Path, Name: outermostName(v.Err), Message: v.Err.Error()})
+	}
+	return json.Marshal(je)
+}
+
+// jsonLayerOf converts l to its JSON representation.
+func jsonLayerOf(l Layer) jsonLayer {
+	jl := jsonLayer{
+		Kind:    l.Kind.String(),
+		Message: l.Message,
+		Public:  l.PublicMessage,
+		Detail:  l.Detail,
+	}
+
+	switch {
+	case l.ErrorCode != nil:
+		jl.Name = errorCodeName(l.ErrorCode)
+		jl.Code = l.ErrorCode.Code()
+		jl.Msg = l.ErrorCode.Msg()
+	case l.Definition != nil:
+		jl.Name = definitionName(l.Definition)
+		jl.Desc = l.Definition.Desc()
+	}
+
+	if len(l.Fields) > 0 {
+		jl.Fields = make(map[string]interface{}, len(l.Fields))
+		for _, f := range l.Fields {
+			f = Redact(f)
+			jl.Fields[f.Key] = f.Value
+		}
+	}
+
+	if l.Frame.PC != 0 && Config.RawPC {
+		jl.Frame = &jsonFrame{PC: fmt.Sprintf("%#x", l.Frame.PC)}
+	} else if l.Frame.PC != 0 {
+		jl.Frame = &jsonFrame{
+			Function: l.Frame.Function,
+			File:     l.Frame.File,
+			Line:     l.Frame.Line,
+		}
+	}
+
+	return jl
+}
+
+// MarshalJSON implements the json.Marshaler interface, see the package-level MarshalJSON function.
+func (e *withCause) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(e)
+}
+
+// MarshalJSON implements the json.Marshaler interface, see the package-level MarshalJSON function.
+func (e *withMessage) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(e)
+}
+
+// MarshalJSON implements the json.Marshaler interface, see the package-level MarshalJSON function.
+func (e *withDefinition) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(e)
+}
+
+// MarshalJSON implements the json.Marshaler interface, see the package-level MarshalJSON function.
+func (e *withErrorCode) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(e)
+}
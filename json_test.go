@@ -0,0 +1,76 @@
+package ppcerrors
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	errCode := NewErrorCode("ErrInternalServerError", 500, "Internal server error")
+	def := NewTypedDefinition[balanceDetail]("ErrBalanceTooLow", "Balance too low")
+
+	t.Run("Encode every layer", func(t *testing.T) {
+		err := errCode.Wrap(WithFields(def.Wrap(errors.New("root"), balanceDetail{Required: 100, Available: 30}, "Buy failed"), F("uid", 123)), "Login failed")
+		actual, _ := json.Marshal(err)
+		expected := `{"error":"ErrInternalServerError, Code=500, Msg=Internal server error, Login failed \u003c= ErrBalanceTooLow, Balance too low, Buy failed, uid=123 \u003c= root",` +
+			`"layers":[{"kind":"errorCode","name":"ErrInternalServerError","code":500,"msg":"Internal server error","message":"Login failed"},` +
+			`{"kind":"definition","name":"ErrBalanceTooLow","desc":"Balance too low","message":"Buy failed","fields":{"uid":123},"detail":{"required":100,"available":30}},` +
+			`{"kind":"foreign","message":"root"}]}`
+		if string(actual) != expected {
+			t.Errorf("Expected JSON '%s', got '%s'", expected, actual)
+		}
+	})
+
+	t.Run("Encode the frame", func(t *testing.T) {
+		Config.Caller = true
+		defer func() { Config.Caller = false }()
+
+		var decoded struct {
+			Layers []jsonLayer `json:"layers"`
+		}
+		actual, _ := json.Marshal(errCode.New())
+		if err := json.Unmarshal(actual, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Layers[0].Frame == nil || decoded.Layers[0].Frame.Line == 0 {
+			t.Errorf("Expected the frame to be encoded, got '%s'", actual)
+		}
+	})
+
+	t.Run("Nil error", func(t *testing.T) {
+		actual, _ := MarshalJSON(nil)
+		if string(actual) != "null" {
+			t.Errorf("Expected 'null', got '%s'", actual)
+		}
+	})
+}
+
+func TestMarshalJSONTimestamps(t *testing.T) {
+	Config.Timestamps = true
+	defer func() { Config.Timestamps = false }()
+
+	errUpdateOneFailed := NewDefinition("ErrUpdateOneFailed", "db.UpdateOne failed")
+	err := Wrap(errUpdateOneFailed.Wrap(errors.New("mock mongodb error")), "SaveUser failed")
+
+	data, _ := json.Marshal(err)
+	var decoded struct {
+		Layers []struct {
+			Time    *time.Time `json:"time"`
+			Elapsed string     `json:"elapsed"`
+		} `json:"layers"`
+	}
+	if e := json.Unmarshal(data, &decoded); e != nil || len(decoded.Layers) != 3 {
+		t.Fatalf("Expected 3 layers, got '%s'", data)
+	}
+	if decoded.Layers[0].Elapsed == "" || decoded.Layers[0].Time != nil {
+		t.Errorf("Expected the outer layer to encode the elapsed time, got '%s'", data)
+	}
+	if decoded.Layers[1].Time == nil || decoded.Layers[1].Elapsed != "" {
+		t.Errorf("Expected the base layer to encode the time, got '%s'", data)
+	}
+	if decoded.Layers[2].Time != nil || decoded.Layers[2].Elapsed != "" {
+		t.Errorf("Expected the foreign layer to encode no time, got '%s'", data)
+	}
+}
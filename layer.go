@@ -0,0 +1,236 @@
+package ppcerrors
+
+import (
+	"iter"
+	"runtime"
+	"time"
+)
+
+type (
+	// LayerKind tells which kind of error a Layer represents.
+	LayerKind int
+
+	// Layer is a read-only view of a single error in an error chain.
+	// Err is the error value of the layer itself, for a layer created by Wrap, Definition.Wrap or ErrorCode.Wrap
+	// it excludes the cause, so Err.Error() only prints the current layer.
+	// Definition and ErrorCode are set according to Kind, Message is the additional information attached
+	// when the layer was created, or the result of Err.Error() for a ForeignLayer,
+	// it is meant for diagnosis and must not be shown to clients, unlike PublicMessage.
+	//
+	// Error types outside this package take part in the layer view by implementing the following methods:
+	// WithErrorCoder or WithDefinitioner to be a ErrorCodeLayer or DefinitionLayer,
+	// Message() string to provide Message, PC() uintptr to provide Frame, Fields() []Field to provide Fields,
+	// Detail() interface{} to provide Detail, Retryability() Retryability to provide Retryability,
+	// Severity() Severity to provide Severity, PublicMessage() string to provide PublicMessage,
+	// Logged() bool to provide Logged, InstanceID() string to provide InstanceID, and Time() time.Time to provide Time.
+	Layer struct {
+		Kind          LayerKind
+		Err           error
+		Definition    Definer
+		ErrorCode     ErrorCoder
+		Message       string
+		Frame         Frame
+		Fields        []Field
+		Detail        interface{}
+		Retryability  Retryability
+		Severity      Severity
+		PublicMessage string
+		Logged        bool
+		InstanceID    string
+		Time          time.Time
+	}
+
+	// Frame is the location where a layer was created.
+	// It is the zero value when Config.Caller was false at that time. The layers passed to Formatter.Text only have its PC,
+	// the function, file, and line are only resolved for Formatter.Verbose and Walk.
+	Frame struct {
+		PC       uintptr
+		Function string
+		File     string
+		Line     int
+	}
+)
+
+const (
+	// ForeignLayer is an error not created by this package, e.g.: errors.New("mock mongodb error").
+	ForeignLayer LayerKind = iota
+	// MessageLayer is an error created by Wrap.
+	MessageLayer
+	// DefinitionLayer is an error implementing WithDefinitioner, e.g.: one created by Definition.New or Definition.Wrap.
+	DefinitionLayer
+	// ErrorCodeLayer is an error implementing WithErrorCoder, e.g.: one created by ErrorCode.New or ErrorCode.Wrap.
+	ErrorCodeLayer
+)
+
+func (k LayerKind) String() string {
+	switch k {
+	case MessageLayer:
+		return "message"
+	case DefinitionLayer:
+		return "definition"
+	case ErrorCodeLayer:
+		return "errorCode"
+	default:
+		return "foreign"
+	}
+}
+
+// frameOf resolves the function name, file name, and line number of pc,
+// the same way as errors.Frame from the github.com/pkg/errors package does.
+func frameOf(pc uintptr) Frame {
+	if pc == 0 {
+		return Frame{}
+	}
+	f := Frame{PC: pc}
+	if fn := runtime.FuncForPC(pc - 1); fn != nil {
+		f.Function = fn.Name()
+		f.File, f.Line = fn.FileLine(pc - 1)
+	}
+	return f
+}
+
+// layerOf builds the Layer view of the single error err without looking into its cause.
+func layerOf(err error) Layer {
+	l := unresolvedLayerOf(err)
+	l.Frame = frameOf(l.Frame.PC)
+	return l
+}
+
+// unresolvedLayerOf is like layerOf, but only sets the PC of the frame, without resolving its function, file, and line,
+// which is costly, so that Error() does not pay for the frames it does not print.
+func unresolvedLayerOf(err error) Layer {
+	l := Layer{Kind: ForeignLayer, Err: err}
+	switch e := err.(type) {
+	case WithErrorCoder:
+		l.Kind = ErrorCodeLayer
+		l.ErrorCode = e.ErrorCode()
+	case WithDefinitioner:
+		l.Kind = DefinitionLayer
+		l.Definition = e.Definition()
+	case *withMessage:
+		l.Kind = MessageLayer
+	}
+
+	if m, ok := err.(interface{ Message() string }); ok {
+		l.Message = m.Message()
+	} else if l.Kind == ForeignLayer {
+		l.Message = err.Error()
+	}
+	if p, ok := err.(interface{ PC() uintptr }); ok {
+		l.Frame = Frame{PC: p.PC()}
+	}
+	if f, ok := err.(interface{ Fields() []Field }); ok {
+		l.Fields = f.Fields()
+	}
+	if d, ok := err.(interface{ Detail() interface{} }); ok {
+		l.Detail = d.Detail()
+	}
+	if r, ok := err.(interface{ Retryability() Retryability }); ok {
+		l.Retryability = r.Retryability()
+	}
+	if s, ok := err.(interface{ Severity() Severity }); ok {
+		l.Severity = s.Severity()
+	}
+	if p, ok := err.(interface{ PublicMessage() string }); ok {
+		l.PublicMessage = p.PublicMessage()
+	}
+	if g, ok := err.(interface{ Logged() bool }); ok {
+		l.Logged = g.Logged()
+	}
+	if i, ok := err.(interface{ InstanceID() string }); ok {
+		l.InstanceID = i.InstanceID()
+	}
+	if t, ok := err.(interface{ Time() time.Time }); ok {
+		l.Time = t.Time()
+	}
+	return l
+}
+
+// Walk calls fn for each layer in err's chain, from the outermost layer to the innermost one,
+// until fn returns false or the chain is exhausted.
+//
+// Errors not created by this package are visited as ForeignLayer and then unwrapped through
+// their Unwrap() error or Unwrap() []error methods, the latter are visited depth-first.
+func Walk(err error, fn func(Layer) bool) {
+	walk(err, fn)
+}
+
+// walk implements Walk and reports whether the walk should continue.
+func walk(err error, fn func(Layer) bool) bool {
+	return walkErrors(err, func(e error) bool {
+		return fn(layerOf(e))
+	})
+}
+
+// walkErrors calls fn for the error value of each layer in err's chain, in the same order as Walk, until fn returns false,
+// and reports whether the walk should continue. Unlike Walk, it does not build Layer values, which resolves frames,
+// so it is used by the checks called on hot paths such as HasDefinition.
+func walkErrors(err error, fn func(error) bool) bool {
+	for err != nil {
+		if e, ok := err.(*withCause); ok {
+			if !fn(e.error) {
+				return false
+			}
+			err = e.cause
+			continue
+		}
+
+		if !fn(err) {
+			return false
+		}
+
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			err = u.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, cause := range u.Unwrap() {
+				if !walkErrors(cause, fn) {
+					return false
+				}
+			}
+			return true
+		default:
+			return true
+		}
+	}
+	return true
+}
+
+// definitionOf returns the definition of the layer err, which is nil unless err is a DefinitionLayer, see layerOf.
+func definitionOf(err error) Definer {
+	if _, ok := err.(WithErrorCoder); ok {
+		return nil
+	}
+	if e, ok := err.(WithDefinitioner); ok {
+		return e.Definition()
+	}
+	return nil
+}
+
+// errorCodeOf returns the error code of the layer err, which is nil unless err is an ErrorCodeLayer, see layerOf.
+func errorCodeOf(err error) ErrorCoder {
+	if e, ok := err.(WithErrorCoder); ok {
+		return e.ErrorCode()
+	}
+	return nil
+}
+
+// Layers returns an iterator over the layers in err's chain in the same order as Walk.
+func Layers(err error) iter.Seq[Layer] {
+	return func(yield func(Layer) bool) {
+		Walk(err, yield)
+	}
+}
+
+// Root returns the innermost cause of err, found by repeatedly calling Unwrap.
+// It returns err itself when err does not wrap any error, and nil when err is nil.
+func Root(err error) error {
+	for err != nil {
+		cause := Unwrap(err)
+		if cause == nil {
+			return err
+		}
+		err = cause
+	}
+	return nil
+}
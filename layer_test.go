@@ -0,0 +1,132 @@
+package ppcerrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	def := NewDefinition("ErrUpdateOneFailed", "db.UpdateOne failed")
+	errCode := NewErrorCode("ErrInternalServerError", 500, "Internal server error")
+	root := errors.New("mock mongodb error")
+	err := errCode.Wrap(Wrap(def.Wrap(root, "SaveUser failed"), "retry exhausted"), "Login failed")
+
+	t.Run("Visit every layer from the outermost to the innermost", func(t *testing.T) {
+		var kinds []LayerKind
+		var messages []string
+		Walk(err, func(l Layer) bool {
+			kinds = append(kinds, l.Kind)
+			messages = append(messages, l.Message)
+			return true
+		})
+
+		expectedKinds := []LayerKind{ErrorCodeLayer, MessageLayer, DefinitionLayer, ForeignLayer}
+		if fmt.Sprint(kinds) != fmt.Sprint(expectedKinds) {
+			t.Errorf("Expected kinds %v, got %v", expectedKinds, kinds)
+		}
+
+		expectedMessages := []string{"Login failed", "retry exhausted", "SaveUser failed", "mock mongodb error"}
+		if fmt.Sprint(messages) != fmt.Sprint(expectedMessages) {
+			t.Errorf("Expected messages %v, got %v", expectedMessages, messages)
+		}
+	})
+
+	t.Run("Layer exposes definition and error code", func(t *testing.T) {
+		var layers []Layer
+		Walk(err, func(l Layer) bool {
+			layers = append(layers, l)
+			return true
+		})
+
+		if layers[0].ErrorCode != errCode {
+			t.Error("Expected the first layer to hold the error code")
+		}
+		if layers[2].Definition != def {
+			t.Error("Expected the third layer to hold the definition")
+		}
+		if layers[0].Err.Error() != "ErrInternalServerError, Code=500, Msg=Internal server error, Login failed" {
+			t.Errorf("Expected the layer error to exclude the cause, got '%s'", layers[0].Err.Error())
+		}
+	})
+
+	t.Run("Stop when fn returns false", func(t *testing.T) {
+		count := 0
+		Walk(err, func(l Layer) bool {
+			count++
+			return l.Kind != MessageLayer
+		})
+		if count != 2 {
+			t.Errorf("Expected 2 layers to be visited, got %d", count)
+		}
+	})
+
+	t.Run("Walk through foreign wrappers and joined errors", func(t *testing.T) {
+		joined := errors.Join(def.New("first"), errCode.New("second"))
+		var kinds []LayerKind
+		Walk(fmt.Errorf("wrapped: %w", joined), func(l Layer) bool {
+			kinds = append(kinds, l.Kind)
+			return true
+		})
+
+		expectedKinds := []LayerKind{ForeignLayer, ForeignLayer, DefinitionLayer, ErrorCodeLayer}
+		if fmt.Sprint(kinds) != fmt.Sprint(expectedKinds) {
+			t.Errorf("Expected kinds %v, got %v", expectedKinds, kinds)
+		}
+	})
+
+	t.Run("Nil error", func(t *testing.T) {
+		Walk(nil, func(l Layer) bool {
+			t.Error("Expected fn not to be called")
+			return true
+		})
+	})
+}
+
+func TestLayers(t *testing.T) {
+	Config.Caller = true
+	defer func() { Config.Caller = false }()
+
+	err := Wrap(errors.New("root"), "wrapped")
+
+	var layers []Layer
+	for l := range Layers(err) {
+		layers = append(layers, l)
+	}
+
+	if len(layers) != 2 {
+		t.Fatalf("Expected 2 layers, got %d", len(layers))
+	}
+	if layers[0].Frame.Function != "github.com/ppc-games/ppcerrors.TestLayers" {
+		t.Errorf("Expected the frame function to be TestLayers, got '%s'", layers[0].Frame.Function)
+	}
+	if layers[0].Frame.Line == 0 || layers[0].Frame.File == "" {
+		t.Error("Expected the frame to contain the file and line")
+	}
+	if layers[1].Frame != (Frame{}) {
+		t.Error("Expected the foreign layer to have an empty frame")
+	}
+}
+
+func TestRoot(t *testing.T) {
+	root := errors.New("root")
+
+	t.Run("Wrapped error", func(t *testing.T) {
+		err := NewErrorCode("ErrInternalServerError", 500, "Internal server error").Wrap(Wrap(root, "wrapped"))
+		if Root(err) != root {
+			t.Error("Expected Root to return the innermost cause")
+		}
+	})
+
+	t.Run("Error without cause", func(t *testing.T) {
+		if Root(root) != root {
+			t.Error("Expected Root to return the error itself")
+		}
+	})
+
+	t.Run("Nil error", func(t *testing.T) {
+		if Root(nil) != nil {
+			t.Error("Expected Root to return nil")
+		}
+	})
+}
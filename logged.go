@@ -0,0 +1,75 @@
+package ppcerrors
+
+import (
+	"context"
+	"log/slog"
+)
+
+// MarkLogged marks err as logged and returns the new error, the mark survives further wrapping,
+// so that the layers that wrap err later can be told apart from those already logged, see RenderUnlogged.
+// See WithFields for how the outermost layer is copied. MarkLogged returns nil when err is nil.
+func MarkLogged(err error) error {
+	if err == nil {
+		return nil
+	}
+	return amend(err, func(a *attrs) {
+		a.logged = true
+	})
+}
+
+// IsLogged reports whether any layer in err's chain has been marked by MarkLogged.
+func IsLogged(err error) bool {
+	logged := false
+	Walk(err, func(l Layer) bool {
+		logged = l.Logged
+		return !logged
+	})
+	return logged
+}
+
+// RenderUnlogged renders the layers of err added since err was last marked by MarkLogged with the formatter of err,
+// followed by Config.ErrorChainSeparator and "(logged)" when some layers are left out, e.g.:
+//
+//	ErrInternalServerError, Code=500, Msg=Internal server error, Login failed <= (logged)
+//
+// verbose selects Formatter.Verbose instead of Formatter.Text. RenderUnlogged returns "" when err is nil or has no new layer.
+func RenderUnlogged(err error, verbose bool) string {
+	if err == nil {
+		return ""
+	}
+
+	layers := chainOf(err, verbose)
+	for i, l := range layers {
+		if !l.Logged {
+			continue
+		}
+
+		for _, n := range layers[:i] {
+			if !isEmpty(n) {
+				return render(formatterOf(err), layers[:i], verbose) + Config.ErrorChainSeparator + "(logged)"
+			}
+		}
+		return ""
+	}
+	return render(formatterOf(err), layers, verbose)
+}
+
+// Log logs the layers of err added since it was last logged by logger at the level mapped from SeverityOf(err),
+// with the message "error" and the attribute error set to RenderUnlogged(err, Config.Caller),
+// and returns err marked by MarkLogged. Log does nothing when err has no new layer, and returns nil when err is nil.
+//
+// e.g.: the repository, the service, and the handler can all call Log without printing the same chain three times:
+//
+//	if err := SaveUser(ctx, user); err != nil {
+//		return ppcerrors.Log(ctx, logger, ErrInternalServerError.Wrap(err, "Login failed"))
+//	}
+func Log(ctx context.Context, logger *slog.Logger, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if text := RenderUnlogged(err, Config.Caller); text != "" {
+		logger.Log(ctx, SeverityOf(err).Level(), "error", slog.String("error", text))
+	}
+	return MarkLogged(err)
+}
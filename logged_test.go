@@ -0,0 +1,104 @@
+package ppcerrors
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestMarkLogged(t *testing.T) {
+	errUpdateOneFailed := NewDefinition("ErrUpdateOneFailed", "db.UpdateOne failed")
+	errInternalServerError := NewErrorCode("ErrInternalServerError", 500, "Internal server error")
+
+	t.Run("The mark survives further wrapping", func(t *testing.T) {
+		err := MarkLogged(errUpdateOneFailed.Wrap(errors.New("root"), "SaveUser failed"))
+		if !IsLogged(err) || !IsLogged(errInternalServerError.Wrap(err, "Login failed")) {
+			t.Error("Expected IsLogged to return true")
+		}
+		if IsLogged(errUpdateOneFailed.New()) {
+			t.Error("Expected IsLogged to return false for a new error")
+		}
+	})
+
+	t.Run("Render only the new layers", func(t *testing.T) {
+		err := MarkLogged(errUpdateOneFailed.Wrap(errors.New("root"), "SaveUser failed"))
+		if actual := RenderUnlogged(err, false); actual != "" {
+			t.Errorf("Expected nothing to render, got '%s'", actual)
+		}
+
+		err = errInternalServerError.Wrap(err, "Login failed")
+		expected := "ErrInternalServerError, Code=500, Msg=Internal server error, Login failed <= (logged)"
+		if actual := RenderUnlogged(err, false); actual != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, actual)
+		}
+	})
+
+	t.Run("Render a foreign error marked as logged", func(t *testing.T) {
+		err := Wrap(MarkLogged(errors.New("root")), "wrapped")
+		if actual := RenderUnlogged(err, false); actual != "wrapped <= (logged)" {
+			t.Errorf("Expected 'wrapped <= (logged)', got '%s'", actual)
+		}
+		if MarkLogged(errors.New("root")).Error() != "root" {
+			t.Error("Expected the mark not to change the error message")
+		}
+	})
+
+	t.Run("Keep matching the original error", func(t *testing.T) {
+		sentinel := errUpdateOneFailed.New("SaveUser failed")
+		wrapped := errUpdateOneFailed.Wrap(errors.New("root"), "SaveUser failed")
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+		for _, original := range []error{sentinel, wrapped} {
+			marked := MarkLogged(original)
+			if !errors.Is(marked, original) || !errors.Is(WithFields(marked, F("uid", 123)), original) {
+				t.Errorf("Expected the marked error to match '%v'", original)
+			}
+			if !errors.Is(errInternalServerError.Wrap(Log(context.Background(), logger, original), "Login failed"), original) {
+				t.Errorf("Expected the error returned by Log to match '%v'", original)
+			}
+		}
+		if errors.Is(MarkLogged(sentinel), errUpdateOneFailed.New("SaveUser failed")) {
+			t.Error("Expected the marked error not to match another error")
+		}
+	})
+
+	t.Run("Nil error", func(t *testing.T) {
+		if MarkLogged(nil) != nil || IsLogged(nil) || RenderUnlogged(nil, true) != "" {
+			t.Error("Expected nil error to be ignored")
+		}
+	})
+}
+
+func TestLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	ctx := context.Background()
+
+	errNotFound := NewDefinition("ErrNotFound", "Not found").MarkSeverity(SeverityWarn)
+	errInternalServerError := NewErrorCode("ErrInternalServerError", 500, "Internal server error").MarkSeverity(SeverityError)
+
+	err := Log(ctx, logger, errNotFound.Wrap(errors.New("root"), "repository"))
+	err = Log(ctx, logger, err)
+	err = Log(ctx, logger, errInternalServerError.Wrap(err, "handler"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 log lines, got %d: %s", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[0], "level=WARN") || !strings.Contains(lines[0], `error="ErrNotFound, Not found, repository <= root"`) {
+		t.Errorf("Expected the whole chain to be logged at WARN first, got '%s'", lines[0])
+	}
+	if !strings.Contains(lines[1], "level=ERROR") || !strings.Contains(lines[1], `error="ErrInternalServerError, Code=500, Msg=Internal server error, handler <= (logged)"`) {
+		t.Errorf("Expected only the new layer to be logged at ERROR, got '%s'", lines[1])
+	}
+	if !IsLogged(err) {
+		t.Error("Expected the returned error to be marked as logged")
+	}
+	if Log(ctx, logger, nil) != nil {
+		t.Error("Expected nil error to be ignored")
+	}
+}
@@ -0,0 +1,43 @@
+package ppcerrors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// multiCause is an error with several causes, e.g.: the violations of ValidationErrors or the task errors of a Collector.
+// It is embedded by the types that keep what each cause is about, which provide its Error, Unwrap, and Format methods.
+type multiCause struct {
+	// label prefixes each cause printed with %+v, e.g.: violation.
+	label string
+	errs  []error
+}
+
+// Error joins the errors of the causes with "; ".
+func (e *multiCause) Error() string {
+	texts := make([]string, 0, len(e.errs))
+	for _, err := range e.errs {
+		texts = append(texts, err.Error())
+	}
+	return strings.Join(texts, "; ")
+}
+
+// Unwrap returns the causes, it implements the Unwrap interface of errors.Join in the errors standard library.
+func (e *multiCause) Unwrap() []error {
+	return e.errs
+}
+
+// Format prints each cause in its own line prefixed by the label with %+v, including its error chain, otherwise it prints Error().
+func (e *multiCause) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		for i, err := range e.errs {
+			if i > 0 {
+				_, _ = io.WriteString(s, "\n")
+			}
+			_, _ = fmt.Fprintf(s, "%s: %+v", e.label, err)
+		}
+		return
+	}
+	_, _ = io.WriteString(s, e.Error())
+}
@@ -0,0 +1,36 @@
+package ppcerrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestMultiCause(t *testing.T) {
+	errRequired := NewDefinition("ErrRequired", "Field is required")
+	first, second := errRequired.New("name"), errors.New("mock mongodb error")
+	err := &multiCause{label: "item", errs: []error{first, second}}
+
+	t.Run("Error", func(t *testing.T) {
+		expected := "ErrRequired, Field is required, name; mock mongodb error"
+		if err.Error() != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, err.Error())
+		}
+	})
+
+	t.Run("Unwrap", func(t *testing.T) {
+		if !errors.Is(err, second) || !HasDefinition(err, errRequired) {
+			t.Error("Expected every cause to be found")
+		}
+	})
+
+	t.Run("Format", func(t *testing.T) {
+		expected := "item: ErrRequired, Field is required, name\nitem: mock mongodb error"
+		if actual := fmt.Sprintf("%+v", err); actual != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, actual)
+		}
+		if actual := fmt.Sprintf("%v", err); actual != err.Error() {
+			t.Errorf("Expected '%s', got '%s'", err.Error(), actual)
+		}
+	})
+}
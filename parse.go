@@ -0,0 +1,235 @@
+package ppcerrors
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// parsedDefinition is a definition reconstructed by ParseText or ParsePlusV.
+	parsedDefinition struct {
+		name string
+		desc string
+	}
+
+	// parsedErrorCode is an error code reconstructed by ParseText or ParsePlusV.
+	parsedErrorCode struct {
+		name string
+		code int
+		msg  string
+	}
+)
+
+func (d *parsedDefinition) Name() string { return d.name }
+func (d *parsedDefinition) Desc() string { return d.desc }
+
+func (c *parsedErrorCode) Name() string { return c.name }
+func (c *parsedErrorCode) Code() int    { return c.code }
+func (c *parsedErrorCode) Msg() string  { return c.msg }
+
+var (
+	// parsedErrorCodeName matches the names of error codes, which can be any Go identifier, e.g.: CodeNotFound,
+	// because the "Code=" and "Msg=" messages that follow tell an error code layer apart.
+	parsedErrorCodeName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// parsedErrorCodeHeader matches the beginning of an error code layer, which TextFormatter always prints with ", "
+	// whatever Config.MessagesSeparator is, e.g.: CodeNotFound, Code=404, Msg=.
+	parsedErrorCodeHeader = regexp.MustCompile(`^(\S+), Code=(-?\d+), Msg=`)
+	// parsedDefinitionName matches the names of definitions, which must start with Err, e.g.: ErrUpdateOneFailed,
+	// because nothing else tells "<name>, <desc>" apart from the messages of a MessageLayer.
+	parsedDefinitionName = regexp.MustCompile(`^Err[A-Za-z0-9_]*$`)
+	// parsedField matches a field printed by Field.String, e.g.: uid=123.
+	parsedField = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_.\-]*)=(.*)$`)
+	// parsedInstance matches the instance ID appended by TextFormatter.Text.
+	parsedInstance = regexp.MustCompile(` \(instance: ([^ ()]+)\)$`)
+	// parsedFrameLine matches the file and line number printed by %+v below the function, e.g.: /src/login.go:27.
+	parsedFrameLine = regexp.MustCompile(`^\s+(.+):(\d+)$`)
+)
+
+// ParseText reconstructs the layers of an error chain from its Error() output, e.g.:
+//
+//	ErrInternalServerError, Code=500, Msg=Internal server error, Login failed <= ErrUpdateOneFailed, db.UpdateOne failed, SaveUser failed, uid=123 <= mock mongodb error
+//
+// so that historical logs can be analyzed with the same Layer model as live errors.
+// Layers are split by Config.ErrorChainSeparator, and their messages by Config.MessagesSeparator:
+//   - "<name>, Code=<code>, Msg=<msg>", where the name is any Go identifier, e.g.: CodeNotFound, starts an ErrorCodeLayer.
+//   - "<name>, <desc>", where the name starts with Err, e.g.: ErrUpdateOneFailed, starts a DefinitionLayer.
+//     Definitions named otherwise cannot be told apart from messages, so their layers are parsed as MessageLayers.
+//   - Other layers are MessageLayers, except the last one, which is a ForeignLayer.
+//
+// The remaining messages are joined back into Message, except the trailing ones in the key=value form, which become Fields
+// whose values are strings. Definition and ErrorCode only implement Definer and ErrorCoder, and Err returns the text of the layer.
+// The instance ID appended to the text, if any, is set to every layer but the ForeignLayer.
+//
+// Parsing is best effort: a message that contains a separator, or looks like a field or a name, is parsed accordingly.
+// ParseText returns nil when s is empty.
+func ParseText(s string) []Layer {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+
+	id := ""
+	if m := parsedInstance.FindStringSubmatchIndex(s); m != nil {
+		id = s[m[2]:m[3]]
+		s = s[:m[0]]
+	}
+
+	parts := strings.Split(s, Config.ErrorChainSeparator)
+	layers := make([]Layer, 0, len(parts))
+	for i, part := range parts {
+		l := parseLayer(part, i == len(parts)-1)
+		if l.Kind != ForeignLayer {
+			l.InstanceID = id
+		}
+		layers = append(layers, l)
+	}
+	return layers
+}
+
+// ParsePlusV reconstructs the layers of an error chain from its %+v output, e.g.:
+//
+//	ErrInternalServerError, Code=500, Msg=Internal server error, Login failed
+//	    at github.com/ppc-games/ppcerrors_test.Login
+//	        /Users/liangrui/Projects/go/ppcerrors/example_test.go:27
+//	cause: mock mongodb error
+//
+// Each layer is parsed as by ParseText, and Frame is reconstructed from the lines below it, with a zero PC,
+// or only the PC when Config.RawPC was true. The time and elapsed lines printed when Config.Timestamps was true
+// are reconstructed into Time, and the instance line into InstanceID. The lines that are not recognized,
+// e.g.: the stack trace of an error created by github.com/pkg/errors, are kept in the Message of their layer.
+// ParsePlusV returns nil when s is empty.
+func ParsePlusV(s string) []Layer {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+
+	var (
+		layers  []Layer
+		elapsed = map[int]time.Duration{}
+		base    = -1
+		id      string
+		current *Layer
+		extra   []string
+		inFrame bool
+	)
+	finish := func() {
+		if current == nil {
+			return
+		}
+		if len(extra) > 0 {
+			current.Message += "\n" + strings.Join(extra, "\n")
+		}
+		layers = append(layers, *current)
+		current, extra, inFrame = nil, nil, false
+	}
+	start := func(text string) {
+		finish()
+		l := Layer{Kind: MessageLayer, Message: text}
+		current = &l
+	}
+
+	for i, line := range strings.Split(s, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case i == 0:
+			start(line)
+		case strings.HasPrefix(line, "cause: "):
+			start(strings.TrimPrefix(line, "cause: "))
+		case strings.HasPrefix(line, "instance: "):
+			id = strings.TrimPrefix(line, "instance: ")
+		case strings.HasPrefix(line, "build: "):
+		case strings.HasPrefix(trimmed, "at ") && current != nil && current.Frame == (Frame{}):
+			fn := strings.TrimPrefix(trimmed, "at ")
+			if pc, err := strconv.ParseUint(fn, 0, 64); err == nil && strings.HasPrefix(fn, "0x") {
+				current.Frame.PC = uintptr(pc)
+				continue
+			}
+			current.Frame.Function = fn
+			inFrame = true
+		case inFrame && parsedFrameLine.MatchString(line):
+			m := parsedFrameLine.FindStringSubmatch(line)
+			current.Frame.File = m[1]
+			current.Frame.Line, _ = strconv.Atoi(m[2])
+			inFrame = false
+		case strings.HasPrefix(trimmed, "time: ") && current != nil:
+			if t, err := time.Parse(time.RFC3339Nano, strings.TrimPrefix(trimmed, "time: ")); err == nil {
+				current.Time = t
+				base = len(layers)
+			}
+		case strings.HasPrefix(trimmed, "elapsed: +") && current != nil:
+			if d, err := time.ParseDuration(strings.TrimPrefix(trimmed, "elapsed: +")); err == nil {
+				elapsed[len(layers)] = d
+			}
+		default:
+			extra = append(extra, line)
+		}
+	}
+	finish()
+
+	for i := range layers {
+		frame, t := layers[i].Frame, layers[i].Time
+		layers[i] = parseLayer(layers[i].Message, i == len(layers)-1 && layers[i].Frame == (Frame{}))
+		layers[i].Frame, layers[i].Time = frame, t
+		if d, ok := elapsed[i]; ok && base >= 0 {
+			layers[i].Time = layers[base].Time.Add(d)
+		}
+		if layers[i].Kind != ForeignLayer {
+			layers[i].InstanceID = id
+		}
+	}
+	return layers
+}
+
+// parseLayer parses the text of a single layer, see ParseText. last tells whether it is the last layer of the chain.
+func parseLayer(text string, last bool) Layer {
+	l := Layer{Kind: MessageLayer, Err: errors.New(text)}
+	if last {
+		l.Kind = ForeignLayer
+		l.Message = text
+	}
+
+	header := parsedErrorCodeHeader.FindStringSubmatch(text)
+	tokens := strings.Split(text, Config.MessagesSeparator)
+	switch {
+	case header != nil && isParsedName(header[1], parsedErrorCodeName):
+		code, err := strconv.Atoi(header[2])
+		if err != nil {
+			return l
+		}
+		tokens = strings.Split(text[len(header[0]):], Config.MessagesSeparator)
+		l.Kind = ErrorCodeLayer
+		l.ErrorCode = &parsedErrorCode{name: header[1], code: code, msg: tokens[0]}
+		tokens = tokens[1:]
+	case len(tokens) >= 2 && isParsedName(tokens[0], parsedDefinitionName):
+		l.Kind = DefinitionLayer
+		l.Definition = &parsedDefinition{name: tokens[0], desc: tokens[1]}
+		tokens = tokens[2:]
+	case last:
+		return l
+	}
+
+	n := len(tokens)
+	for n > 0 && parsedField.MatchString(tokens[n-1]) {
+		n--
+	}
+	for _, token := range tokens[n:] {
+		m := parsedField.FindStringSubmatch(token)
+		l.Fields = append(l.Fields, F(m[1], m[2]))
+	}
+	l.Message = strings.Join(tokens[:n], Config.MessagesSeparator)
+	return l
+}
+
+// isParsedName reports whether s is a name matched by re, including a category path, e.g.: ErrDB/ErrUpdateOneFailed.
+func isParsedName(s string, re *regexp.Regexp) bool {
+	for _, name := range strings.Split(s, Config.CategorySeparator) {
+		if !re.MatchString(name) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+package ppcerrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// parsedSummary returns the parts of l that survive the text formats.
+func parsedSummary(l Layer) string {
+	name, code, msg, desc := "", 0, "", ""
+	if l.ErrorCode != nil {
+		name, code, msg = l.ErrorCode.Name(), l.ErrorCode.Code(), l.ErrorCode.Msg()
+	}
+	if l.Definition != nil {
+		name, desc = l.Definition.Name(), l.Definition.Desc()
+	}
+	return fmt.Sprintf("%s|%s|%d|%s|%s|%s|%v", l.Kind, name, code, msg, desc, l.Message, l.Fields)
+}
+
+func TestParseText(t *testing.T) {
+	errUpdateOneFailed := NewDefinition("ErrUpdateOneFailed", "db.UpdateOne failed")
+	errInternalServerError := NewErrorCode("ErrInternalServerError", 500, "Internal server error")
+	err := errInternalServerError.Wrap(Wrap(WithFields(errUpdateOneFailed.Wrap(errors.New("mock mongodb error"), "SaveUser failed"), F("uid", "123")), "retrying"), "Login failed")
+
+	t.Run("Reconstruct every layer", func(t *testing.T) {
+		var expected []string
+		Walk(err, func(l Layer) bool {
+			if !isEmpty(l) {
+				expected = append(expected, parsedSummary(l))
+			}
+			return true
+		})
+
+		layers := ParseText(err.Error())
+		if len(layers) != len(expected) {
+			t.Fatalf("Expected %d layers, got %d", len(expected), len(layers))
+		}
+		for i, l := range layers {
+			if actual := parsedSummary(l); actual != expected[i] {
+				t.Errorf("Expected layer %d to be '%s', got '%s'", i, expected[i], actual)
+			}
+		}
+	})
+
+	t.Run("Render the parsed layers back", func(t *testing.T) {
+		if actual := (TextFormatter{}).Text(ParseText(err.Error())); actual != err.Error() {
+			t.Errorf("Expected '%s', got '%s'", err.Error(), actual)
+		}
+	})
+
+	t.Run("Honour the configured separators", func(t *testing.T) {
+		Config.MessagesSeparator, Config.ErrorChainSeparator = "; ", " <- "
+		defer func() { Config.MessagesSeparator, Config.ErrorChainSeparator = ", ", " <= " }()
+
+		layers := ParseText("ErrUpdateOneFailed; db.UpdateOne failed; SaveUser failed, uid=123 <- EOF")
+		if len(layers) != 2 || layers[0].Kind != DefinitionLayer || layers[0].Message != "SaveUser failed, uid=123" {
+			t.Errorf("Expected a definition layer and a foreign layer, got %v", layers)
+		}
+		layers = ParseText(errInternalServerError.Wrap(errors.New("EOF"), "Login failed").Error())
+		if len(layers) != 2 || layers[0].Kind != ErrorCodeLayer || layers[0].ErrorCode.Msg() != "Internal server error" || layers[0].Message != "Login failed" {
+			t.Errorf("Expected an error code layer and a foreign layer, got %v", layers)
+		}
+	})
+
+	t.Run("Error codes named by any identifier", func(t *testing.T) {
+		codeNotFound := NewErrorCode("CodeNotFound", 404, "Not found")
+		layers := ParseText(codeNotFound.Wrap(errors.New("mock mongodb error"), "LoadRoom failed").Error())
+		if len(layers) != 2 || layers[0].Kind != ErrorCodeLayer || layers[0].ErrorCode.Name() != "CodeNotFound" || layers[0].Message != "LoadRoom failed" {
+			t.Errorf("Expected an error code layer named CodeNotFound, got %v", layers)
+		}
+	})
+
+	t.Run("Definitions must be named Err", func(t *testing.T) {
+		layers := ParseText("Login failed, retrying <= EOF")
+		if layers[0].Kind != MessageLayer || layers[0].Message != "Login failed, retrying" {
+			t.Errorf("Expected a message layer, got %v", layers)
+		}
+	})
+
+	t.Run("Strip the instance ID", func(t *testing.T) {
+		layers := ParseText("ErrUpdateOneFailed, db.UpdateOne failed <= EOF (instance: 019a0f5c3e2b00a7c41f9d3e)")
+		if layers[0].InstanceID != "019a0f5c3e2b00a7c41f9d3e" || layers[1].Message != "EOF" {
+			t.Errorf("Expected the instance ID to be stripped, got %v", layers)
+		}
+	})
+
+	t.Run("Empty text", func(t *testing.T) {
+		if layers := ParseText(" "); layers != nil {
+			t.Errorf("Expected nil, got %v", layers)
+		}
+	})
+}
+
+func TestParsePlusV(t *testing.T) {
+	Config.Caller = true
+	Config.Timestamps = true
+	defer func() {
+		Config.Caller = false
+		Config.Timestamps = false
+	}()
+
+	errUpdateOneFailed := NewDefinition("ErrUpdateOneFailed", "db.UpdateOne failed")
+	errInternalServerError := NewErrorCode("ErrInternalServerError", 500, "Internal server error")
+	err := errInternalServerError.Wrap(errUpdateOneFailed.Wrap(errors.New("mock mongodb error"), "SaveUser failed"), "Login failed")
+
+	layers := ParsePlusV(fmt.Sprintf("%+v", err))
+	live := chainOf(err, true)
+	if len(layers) != len(live) {
+		t.Fatalf("Expected %d layers, got %d", len(live), len(layers))
+	}
+
+	t.Run("Reconstruct the frames", func(t *testing.T) {
+		for i, l := range layers {
+			expected := live[i].Frame
+			expected.PC = 0
+			if l.Frame != expected {
+				t.Errorf("Expected layer %d to have the frame %v, got %v", i, expected, l.Frame)
+			}
+		}
+	})
+
+	t.Run("Reconstruct the times", func(t *testing.T) {
+		for i, l := range layers[:2] {
+			if d := l.Time.Sub(live[i].Time); d < -time.Microsecond || d > time.Microsecond {
+				t.Errorf("Expected layer %d to be created at %s, got %s", i, live[i].Time, l.Time)
+			}
+		}
+	})
+
+	t.Run("Keep unrecognized lines", func(t *testing.T) {
+		layers := ParsePlusV("ErrUpdateOneFailed, db.UpdateOne failed\ncause: mock mongodb error\nmain.main\n\t/src/main.go:10")
+		if layers[1].Kind != ForeignLayer || layers[1].Message != "mock mongodb error\nmain.main\n\t/src/main.go:10" || layers[1].Frame.File != "" {
+			t.Errorf("Expected the stack trace to be kept in the message, got %v", layers[1])
+		}
+	})
+}
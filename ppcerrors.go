@@ -56,9 +56,9 @@ For example, defining errors that occur when performing mongo operations:
 		ErrNoDocumentWasUpdated = ppcerrors.NewDefinition("ErrNoDocumentWasUpdated", "No document was updated")
 	)
 
-Note: ErrUpdateOneFailed and ErrNoDocumentWasUpdated are type definition structs, not type error.
+Note: ErrUpdateOneFailed and ErrNoDocumentWasUpdated are of type *ppcerrors.Definition, not type error.
 
-# Create an actual error type using either definition.New or definition.Wrap.
+# Create an actual error type using either Definition.New or Definition.Wrap.
 
 For example, when an error occurs when saving a user to the database,
 you can use the predefined definition to Wrap an existing error with additional information,
@@ -103,9 +103,9 @@ For example, defining error codes that are returned to the client in HTTP respon
 		ErrUnauthorized = ppcerrors.NewErrorCode("ErrUnauthorized", 401, "Unauthorized")
 	)
 
-Note: ErrInternalServerError and ErrUnauthorized are type errorCode structs, not type error.
+Note: ErrInternalServerError and ErrUnauthorized are of type *ppcerrors.ErrorCode, not type error.
 
-# Create an actual error type using either errorCode.New or errorCode.Wrap.
+# Create an actual error type using either ErrorCode.New or ErrorCode.Wrap.
 
 For example, when an error occurs when one user requests the login API:
 
@@ -153,31 +153,95 @@ func Wrap(cause error, message string) error {
 	if cause == nil {
 		return nil
 	}
-	return &withCause{
+	return created(&withCause{
 		error: &withMessage{
-			msg: message,
-			pc:  getPCFromCaller(),
+			msg:   message,
+			pc:    getPCFromCaller(),
+			attrs: newAttrs(cause),
 		},
 		cause: cause,
+	}, cause)
+}
+
+// WrapSkip is like Wrap, but records the location skip frames above the caller of WrapSkip,
+// e.g.: WrapSkip(1, ...) called in a helper function records the caller of the helper function. WrapSkip(0, ...) is the same as Wrap.
+func WrapSkip(skip int, cause error, message string) error {
+	if cause == nil {
+		return nil
 	}
+	return created(&withCause{
+		error: &withMessage{
+			msg:   message,
+			pc:    getPCFromCallerSkip(skip),
+			attrs: newAttrs(cause),
+		},
+		cause: cause,
+	}, cause)
 }
 
-// HasErrorCode returns true if err and its error chain contain the specified error code target.
-func HasErrorCode(err error, target *errorCode) bool {
-	var withErrorCode WithErrorCoder
-	if As(err, &withErrorCode) && withErrorCode.ErrorCode() == target {
-		return true
+// WrapDefer wraps *errp with message and fields like Wrap when *errp is not nil, it is meant to be deferred
+// in a function with a named error result, so that every return path is wrapped with the same context, e.g.:
+//
+//	func LoadRoom(id string) (room *Room, err error) {
+//		defer ppcerrors.WrapDefer(&err, "LoadRoom failed", ppcerrors.F("roomID", id))
+//		...
+//	}
+//
+// When Config.Caller == true, the deferring function is recorded rather than the runtime calling deferred functions,
+// or the function that panicked when the deferred call runs during a panic.
+func WrapDefer(errp *error, message string, fields ...Field) {
+	if errp == nil || *errp == nil {
+		return
 	}
-	return false
+	cause := *errp
+	*errp = created(&withCause{
+		error: &withMessage{
+			msg:   message,
+			pc:    getPCFromDeferrer(),
+			attrs: newAttrs(cause).withFields(fields...),
+		},
+		cause: cause,
+	}, cause)
 }
 
-// HasDefinition returns true if err and its error chain contain the specified definition target.
-func HasDefinition(err error, target *definition) bool {
-	var withDefinition WithDefinitioner
-	if As(err, &withDefinition) && withDefinition.Definition() == target {
-		return true
+// WrapWith creates an error of type withCause whose current error is layer and whose cause is the cause parameter,
+// so that error types outside this package are printed and walked the same way as errors created by Wrap.
+// WrapWith returns nil when the cause parameter is nil, and cause when the layer parameter is nil.
+func WrapWith(cause error, layer error) error {
+	if cause == nil {
+		return nil
+	}
+	if layer == nil {
+		return cause
 	}
-	return false
+	return created(&withCause{
+		error: layer,
+		cause: cause,
+	}, cause)
+}
+
+// HasErrorCode returns true if any layer in err's error chain contains the specified error code target
+// or an error code descending from target, see ErrorCode.NewChild.
+func HasErrorCode(err error, target ErrorCoder) bool {
+	found := false
+	walkErrors(err, func(e error) bool {
+		code := errorCodeOf(e)
+		found = code != nil && errorCodeDescends(code, target)
+		return !found
+	})
+	return found
+}
+
+// HasDefinition returns true if any layer in err's error chain contains the specified definition target
+// or a definition descending from target, see Definition.NewChild.
+func HasDefinition(err error, target Definer) bool {
+	found := false
+	walkErrors(err, func(e error) bool {
+		def := definitionOf(e)
+		found = def != nil && definitionDescends(def, target)
+		return !found
+	})
+	return found
 }
 
 // Is reports whether any error in err's chain matches the target.
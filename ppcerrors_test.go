@@ -2,6 +2,8 @@ package ppcerrors
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -44,8 +46,47 @@ func TestWrap(t *testing.T) {
 	})
 }
 
+func TestWrapDefer(t *testing.T) {
+	loadRoom := func(fail bool) (err error) {
+		defer WrapDefer(&err, "LoadRoom failed", F("roomID", 1))
+		if fail {
+			return errors.New("mock mongodb error")
+		}
+		return nil
+	}
+
+	t.Run("Wrap a non-nil error", func(t *testing.T) {
+		err := loadRoom(true)
+		expected := "LoadRoom failed, roomID=1 <= mock mongodb error"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected '%s', got '%v'", expected, err)
+		}
+	})
+
+	t.Run("Leave a nil error", func(t *testing.T) {
+		if err := loadRoom(false); err != nil {
+			t.Errorf("Expected nil, got %v", err)
+		}
+		WrapDefer(nil, "no error pointer")
+	})
+
+	t.Run("Record the deferring function", func(t *testing.T) {
+		Config.Caller = true
+		defer func() { Config.Caller = false }()
+
+		var frame Frame
+		Walk(loadRoom(true), func(l Layer) bool {
+			frame = l.Frame
+			return false
+		})
+		if frame.Function != "github.com/ppc-games/ppcerrors.TestWrapDefer.func1" {
+			t.Errorf("Expected the deferring function, got '%s'", frame.Function)
+		}
+	})
+}
+
 func TestHasErrorCode(t *testing.T) {
-	errCode := &errorCode{name: "OK", code: 200, msg: "OK"}
+	errCode := &ErrorCode{name: "OK", code: 200, msg: "OK"}
 
 	t.Run("Error with matching error code", func(t *testing.T) {
 		err := &withErrorCode{errCode: errCode}
@@ -55,7 +96,7 @@ func TestHasErrorCode(t *testing.T) {
 	})
 
 	t.Run("Error without matching error code", func(t *testing.T) {
-		anotherErrCode := &errorCode{name: "NotFound", code: 404, msg: "Not Found"}
+		anotherErrCode := &ErrorCode{name: "NotFound", code: 404, msg: "Not Found"}
 		err := &withErrorCode{errCode: anotherErrCode}
 		if HasErrorCode(err, errCode) {
 			t.Error("Expected HasErrorCode to return false")
@@ -68,10 +109,34 @@ func TestHasErrorCode(t *testing.T) {
 			t.Error("Expected HasErrorCode to return false")
 		}
 	})
+
+	t.Run("Wrapped error with matching error code in any layer", func(t *testing.T) {
+		anotherErrCode := &ErrorCode{name: "NotFound", code: 404, msg: "Not Found"}
+		err := anotherErrCode.Wrap(errCode.Wrap(errors.New("root")))
+		if !HasErrorCode(err, errCode) || !HasErrorCode(err, anotherErrCode) {
+			t.Error("Expected HasErrorCode to return true for both error codes")
+		}
+	})
+
+	t.Run("Error type outside this package", func(t *testing.T) {
+		code := customErrorCode{name: "ErrCustom", code: 600}
+		err := fmt.Errorf("wrapped: %w", &customError{errCode: code})
+		if !HasErrorCode(err, code) {
+			t.Error("Expected HasErrorCode to return true")
+		}
+	})
+
+	t.Run("Error code that is not comparable", func(t *testing.T) {
+		code := uncomparableErrorCode{names: []string{"ErrCustom"}}
+		err := WrapWith(errors.New("root"), &uncomparableErrorCodeError{errCode: code})
+		if HasErrorCode(err, code) {
+			t.Error("Expected HasErrorCode to return false without panicking")
+		}
+	})
 }
 
 func TestHasDefinition(t *testing.T) {
-	def := &definition{name: "ErrInvalidConfig", desc: "Invalid configuration"}
+	def := &Definition{name: "ErrInvalidConfig", desc: "Invalid configuration"}
 
 	t.Run("Error with matching definition", func(t *testing.T) {
 		err := &withDefinition{def: def}
@@ -81,7 +146,7 @@ func TestHasDefinition(t *testing.T) {
 	})
 
 	t.Run("Error without matching definition", func(t *testing.T) {
-		anotherDef := &definition{name: "ErrInvalidValue", desc: "Invalid value"}
+		anotherDef := &Definition{name: "ErrInvalidValue", desc: "Invalid value"}
 		err := &withDefinition{def: anotherDef}
 		if HasDefinition(err, def) {
 			t.Error("Expected HasDefinition to return false")
@@ -94,8 +159,116 @@ func TestHasDefinition(t *testing.T) {
 			t.Error("Expected HasDefinition to return false")
 		}
 	})
+
+	t.Run("Wrapped error with matching definition in any layer", func(t *testing.T) {
+		anotherDef := &Definition{name: "ErrInvalidValue", desc: "Invalid value"}
+		err := Wrap(anotherDef.Wrap(def.New("inner")), "outer")
+		if !HasDefinition(err, def) || !HasDefinition(err, anotherDef) {
+			t.Error("Expected HasDefinition to return true for both definitions")
+		}
+	})
+
+	t.Run("Definition that is not comparable", func(t *testing.T) {
+		custom := uncomparableDefinition{names: []string{"ErrCustom"}}
+		err := WrapWith(errors.New("root"), &uncomparableDefinitionError{def: custom})
+		if HasDefinition(err, custom) || HasDefinition(err, def) {
+			t.Error("Expected HasDefinition to return false without panicking")
+		}
+	})
+}
+
+func TestWrapWith(t *testing.T) {
+	Config.Caller = true
+	defer func() { Config.Caller = false }()
+
+	code := customErrorCode{name: "ErrCustom", code: 600}
+	cause := errors.New("root")
+	err := WrapWith(cause, &customError{errCode: code, pc: getPCFromCaller()})
+
+	t.Run("Error", func(t *testing.T) {
+		if err.Error() != "ErrCustom(600) <= root" {
+			t.Errorf("Expected error message 'ErrCustom(600) <= root', got '%s'", err.Error())
+		}
+	})
+
+	t.Run("Format", func(t *testing.T) {
+		actual := fmt.Sprintf("%+v", err)
+		if !strings.HasPrefix(actual, "ErrCustom(600)\n    at ") || !strings.HasSuffix(actual, "\ncause: root") {
+			t.Errorf("Expected the custom error to be printed with its location, got '%s'", actual)
+		}
+	})
+
+	t.Run("Walk", func(t *testing.T) {
+		var layers []Layer
+		Walk(err, func(l Layer) bool {
+			layers = append(layers, l)
+			return true
+		})
+		if len(layers) != 2 || layers[0].Kind != ErrorCodeLayer || layers[0].ErrorCode != code {
+			t.Errorf("Expected the custom error to be an ErrorCodeLayer, got %+v", layers)
+		}
+		if layers[0].Frame.Function == "" {
+			t.Error("Expected the custom error to have a frame")
+		}
+	})
+
+	t.Run("Nil cause or layer", func(t *testing.T) {
+		if WrapWith(nil, &customError{}) != nil {
+			t.Error("Expected nil error when cause is nil")
+		}
+		if WrapWith(cause, nil) != cause {
+			t.Error("Expected cause when layer is nil")
+		}
+	})
+}
+
+// customErrorCode is an ErrorCoder implemented outside the built-in types.
+type customErrorCode struct {
+	name string
+	code int
+}
+
+func (c customErrorCode) Name() string { return c.name }
+func (c customErrorCode) Code() int    { return c.code }
+func (c customErrorCode) Msg() string  { return "" }
+
+// customError is an error type implementing WithErrorCoder outside the built-in types.
+type customError struct {
+	errCode customErrorCode
+	pc      uintptr
+}
+
+func (e *customError) Error() string {
+	return fmt.Sprintf("%s(%d)", e.errCode.name, e.errCode.code)
 }
 
+func (e *customError) ErrorCode() ErrorCoder         { return e.errCode }
+func (e *customError) PC() uintptr                   { return e.pc }
+func (e *customError) Format(s fmt.State, verb rune) { FormatLayer(e, s, verb) }
+
+// uncomparableDefinition and uncomparableErrorCode are implementations that cannot be compared with ==.
+type (
+	uncomparableDefinition struct{ names []string }
+	uncomparableErrorCode  struct{ names []string }
+)
+
+func (d uncomparableDefinition) Name() string { return d.names[0] }
+func (d uncomparableDefinition) Desc() string { return "" }
+func (c uncomparableErrorCode) Name() string  { return c.names[0] }
+func (c uncomparableErrorCode) Code() int     { return 600 }
+func (c uncomparableErrorCode) Msg() string   { return "" }
+
+// uncomparableDefinitionError and uncomparableErrorCodeError are error types holding them.
+type (
+	uncomparableDefinitionError struct{ def Definer }
+	uncomparableErrorCodeError  struct{ errCode ErrorCoder }
+)
+
+func (e *uncomparableDefinitionError) Error() string        { return "uncomparable definition" }
+func (e *uncomparableDefinitionError) Definition() Definer  { return e.def }
+func (e *uncomparableErrorCodeError) Error() string         { return "uncomparable error code" }
+func (e *uncomparableErrorCodeError) ErrorCode() ErrorCoder { return e.errCode }
+
 func TestIs(t *testing.T) {
 	target := errors.New("target error")
 
@@ -117,7 +290,7 @@ func TestIs(t *testing.T) {
 func TestAs(t *testing.T) {
 	var target WithErrorCoder
 
-	err := &withErrorCode{errCode: &errorCode{}}
+	err := &withErrorCode{errCode: &ErrorCode{}}
 
 	t.Run("Error with matching type", func(t *testing.T) {
 		if !As(err, &target) {
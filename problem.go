@@ -0,0 +1,70 @@
+package ppcerrors
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type (
+	// Problem is the RFC 7807 "problem details" representation of an error, it only contains client-safe information:
+	// Title is the PublicMessage of the error, Code and Type come from its outermost error code,
+	// and Instance is its instance ID, which clients can display to correlate their reports with the server logs.
+	// Violations lists the field violations of an error returned by ValidationErrors.Err.
+	Problem struct {
+		Type       string             `json:"type"`
+		Title      string             `json:"title"`
+		Status     int                `json:"status"`
+		Detail     string             `json:"detail,omitempty"`
+		Instance   string             `json:"instance,omitempty"`
+		Code       int                `json:"code,omitempty"`
+		Violations []ProblemViolation `json:"violations,omitempty"`
+	}
+
+	// ProblemViolation is the client-safe representation of a Violation: Name is the name of its definition,
+	// and Message is the message attached to it by WithPublicMessage, if any, see ValidationErrors.AddError.
+	ProblemViolation struct {
+		Path    string `json:"path"`
+		Name    string `json:"name,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+)
+
+// NewProblem returns the Problem of err. Status is the code of the outermost error code of err when it is
+// a valid HTTP status code, otherwise http.StatusInternalServerError. Type is the name of the error code, or "about:blank".
+// NewProblem returns nil when err is nil.
+func NewProblem(err error) *Problem {
+	if err == nil {
+		return nil
+	}
+
+	p := &Problem{
+		Type:     "about:blank",
+		Title:    PublicMessage(err),
+		Status:   http.StatusInternalServerError,
+		Instance: InstanceID(err),
+	}
+	if code := outermostErrorCode(err); code != nil {
+		p.Type = errorCodeName(code)
+		p.Code = code.Code()
+		if p.Code >= 100 && p.Code <= 599 {
+			p.Status = p.Code
+		}
+	}
+	for _, v := range Violations(err) {
+		p.Violations = append(p.Violations, ProblemViolation{Path: v.Path, Name: outermostName(v.Err), Message: publicMessage(v.Err)})
+	}
+	return p
+}
+
+// WriteProblem writes the Problem of err to w as application/problem+json with its status code.
+// WriteProblem writes nothing when err is nil.
+func WriteProblem(w http.ResponseWriter, err error) error {
+	p := NewProblem(err)
+	if p == nil {
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	return json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,66 @@
+package ppcerrors
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestNewProblem(t *testing.T) {
+	errUpdateOneFailed := NewDefinition("ErrUpdateOneFailed", "db.UpdateOne failed")
+
+	t.Run("Status and title from the error code", func(t *testing.T) {
+		errNotFound := NewErrorCode("ErrNotFound", 404, "Not found")
+		p := NewProblem(errNotFound.Wrap(errUpdateOneFailed.New("uid=123"), "GetUser failed"))
+		expected := Problem{Type: "ErrNotFound", Title: "Not found", Status: 404, Code: 404}
+		if !reflect.DeepEqual(*p, expected) {
+			t.Errorf("Expected %v, got %v", expected, *p)
+		}
+	})
+
+	t.Run("Business codes are served as 500", func(t *testing.T) {
+		errBalanceTooLow := NewErrorCode("ErrBalanceTooLow", 10001, "Balance too low")
+		p := NewProblem(errBalanceTooLow.New())
+		if p.Status != 500 || p.Code != 10001 {
+			t.Errorf("Expected status 500 and code 10001, got %d and %d", p.Status, p.Code)
+		}
+	})
+
+	t.Run("No error code", func(t *testing.T) {
+		p := NewProblem(errors.New("mock mongodb error"))
+		expected := Problem{Type: "about:blank", Title: Config.DefaultPublicMessage, Status: 500}
+		if !reflect.DeepEqual(*p, expected) {
+			t.Errorf("Expected %v, got %v", expected, *p)
+		}
+	})
+
+	t.Run("Nil error", func(t *testing.T) {
+		if p := NewProblem(nil); p != nil {
+			t.Errorf("Expected nil, got %v", p)
+		}
+	})
+}
+
+func TestWriteProblem(t *testing.T) {
+	Config.InstanceID = func() string { return "0123456789" }
+	defer func() { Config.InstanceID = nil }()
+
+	errInternalServerError := NewErrorCode("ErrInternalServerError", 500, "Internal server error")
+	w := httptest.NewRecorder()
+	if err := WriteProblem(w, errInternalServerError.Wrap(errors.New("mock mongodb error"))); err != nil {
+		t.Fatal(err)
+	}
+
+	if w.Code != 500 {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("Expected application/problem+json, got '%s'", ct)
+	}
+	var p Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil || p.Instance != "0123456789" {
+		t.Errorf("Expected the instance '0123456789', got '%s'", w.Body.String())
+	}
+}
@@ -0,0 +1,77 @@
+package ppcerrors
+
+import (
+	"strings"
+	"sync"
+)
+
+// sensitiveKeys holds the lower-cased field keys registered by RegisterSensitiveKeys.
+var sensitiveKeys sync.Map
+
+// WithPublicMessage attaches a client-safe message to the outermost layer of err and returns the new error,
+// see WithFields for how the outermost layer is copied. WithPublicMessage returns nil when err is nil.
+func WithPublicMessage(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	return amend(err, func(a *attrs) {
+		a.public = message
+	})
+}
+
+// PublicMessage returns the message of err that is safe to show to clients, it never contains the messages
+// passed to New or Wrap, the descriptions of definitions, or the messages of foreign errors, which are meant for diagnosis.
+// It returns, from the outermost layer to the innermost one, the first of:
+//  1. The message attached by WithPublicMessage.
+//  2. The Msg() of the layer's error code.
+//
+// PublicMessage returns Config.DefaultPublicMessage when no layer provides a client-safe message, and "" when err is nil.
+func PublicMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	if message := publicMessage(err); message != "" {
+		return message
+	}
+	return Config.DefaultPublicMessage
+}
+
+// publicMessage implements PublicMessage, it returns "" when no layer provides a client-safe message.
+func publicMessage(err error) string {
+	message := ""
+	Walk(err, func(l Layer) bool {
+		switch {
+		case l.PublicMessage != "":
+			message = l.PublicMessage
+		case l.ErrorCode != nil:
+			message = l.ErrorCode.Msg()
+		}
+		return message == ""
+	})
+	return message
+}
+
+// RegisterSensitiveKeys registers field keys whose values must not be printed, e.g.: "token", "email".
+// Keys are case-insensitive. The values of these fields are replaced by Config.RedactedValue in Error(), %+v, and JSON.
+func RegisterSensitiveKeys(keys ...string) {
+	for _, key := range keys {
+		sensitiveKeys.Store(strings.ToLower(key), struct{}{})
+	}
+}
+
+// UnregisterSensitiveKeys removes keys registered by RegisterSensitiveKeys.
+func UnregisterSensitiveKeys(keys ...string) {
+	for _, key := range keys {
+		sensitiveKeys.Delete(strings.ToLower(key))
+	}
+}
+
+// Redact returns f with its value replaced by Config.RedactedValue when its key is registered by RegisterSensitiveKeys,
+// otherwise it returns f unchanged. Custom loggers reading Layer.Fields should call it before printing a field.
+func Redact(f Field) Field {
+	if _, ok := sensitiveKeys.Load(strings.ToLower(f.Key)); ok {
+		f.Value = Config.RedactedValue
+	}
+	return f
+}
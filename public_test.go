@@ -0,0 +1,69 @@
+package ppcerrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPublicMessage(t *testing.T) {
+	errUpdateOneFailed := NewDefinition("ErrUpdateOneFailed", "db.UpdateOne failed")
+	errInternalServerError := NewErrorCode("ErrInternalServerError", 500, "Internal server error")
+	errBanned := NewErrorCode("ErrBanned", 403, "Forbidden")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Error code message without internal messages", errInternalServerError.Wrap(errUpdateOneFailed.New("uid: 123"), "Login failed"), "Internal server error"},
+		{"Outermost error code", errInternalServerError.Wrap(errBanned.New("uid: 123")), "Internal server error"},
+		{"Public message override", WithPublicMessage(errBanned.New("uid: 123"), "Your account is banned until tomorrow"), "Your account is banned until tomorrow"},
+		{"Inner error code", Wrap(errBanned.New(), "outer"), "Forbidden"},
+		{"No client-safe message", errUpdateOneFailed.Wrap(errors.New("mongo: timeout"), "uid: 123"), "Internal server error"},
+		{"Nil error", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := PublicMessage(tt.err); actual != tt.expected {
+				t.Errorf("Expected PublicMessage to return '%s', got '%s'", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	RegisterSensitiveKeys("Token", "email")
+	defer UnregisterSensitiveKeys("Token", "email")
+
+	err := WithFields(Wrap(errors.New("root"), "Login failed"), F("token", "secret-token"), F("EMAIL", "a@b.c"), F("uid", 123))
+
+	t.Run("Error", func(t *testing.T) {
+		expected := "Login failed, token=[REDACTED], EMAIL=[REDACTED], uid=123 <= root"
+		if err.Error() != expected {
+			t.Errorf("Expected error message '%s', got '%s'", expected, err.Error())
+		}
+	})
+
+	t.Run("Format", func(t *testing.T) {
+		if actual := fmt.Sprintf("%+v", err); strings.Contains(actual, "secret-token") || strings.Contains(actual, "a@b.c") {
+			t.Errorf("Expected sensitive values to be redacted, got '%s'", actual)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		actual, _ := json.Marshal(err)
+		if strings.Contains(string(actual), "secret-token") || !strings.Contains(string(actual), `"token":"[REDACTED]"`) {
+			t.Errorf("Expected sensitive values to be redacted, got '%s'", actual)
+		}
+	})
+
+	t.Run("Unregistered key", func(t *testing.T) {
+		if f := Redact(F("uid", 123)); f.Value != 123 {
+			t.Errorf("Expected the value to be kept, got %v", f.Value)
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package ppcerrors
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+
+	"github.com/ppc-games/ppcerrors/internal/buildid"
+)
+
+var (
+	buildIDOnce sync.Once
+	buildIDVal  string
+)
+
+// BuildID returns the Go build ID of the running binary, which is kept by -s -w,
+// or "" when it cannot be read, e.g.: the binary is not an ELF binary.
+func BuildID() string {
+	buildIDOnce.Do(func() {
+		if path, err := os.Executable(); err == nil {
+			buildIDVal, _ = buildid.Read(path)
+		}
+	})
+	return buildIDVal
+}
+
+// rawPCAnchor is never called, its address is printed in raw PC mode so that cmd/ppcerrsym can
+// compute how far the binary was relocated when it was loaded, e.g.: a position-independent executable.
+func rawPCAnchor() {}
+
+// rawPCAnchorAddr returns the address of rawPCAnchor in the running binary.
+func rawPCAnchorAddr() uintptr {
+	return reflect.ValueOf(rawPCAnchor).Pointer()
+}
+
+// rawPCTrailer returns the line printed after the layers in raw PC mode, see Config.RawPC, e.g.:
+//
+//	build: Vh2s9Q.../4bKkVtd... anchor: 0x4d2a60
+func rawPCTrailer() string {
+	return fmt.Sprintf("build: %s anchor: %#x", BuildID(), rawPCAnchorAddr())
+}
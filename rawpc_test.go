@@ -0,0 +1,56 @@
+package ppcerrors
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ppc-games/ppcerrors/internal/logfmt"
+)
+
+func TestRawPC(t *testing.T) {
+	Config.Caller = true
+	Config.RawPC = true
+	defer func() {
+		Config.Caller = false
+		Config.RawPC = false
+	}()
+
+	errUpdateOneFailed := NewDefinition("ErrUpdateOneFailed", "db.UpdateOne failed")
+	err := errUpdateOneFailed.New("SaveUser failed")
+	pc := err.(*withDefinition).pc
+
+	t.Run("Print raw program counters and the build line", func(t *testing.T) {
+		expected := fmt.Sprintf("ErrUpdateOneFailed, db.UpdateOne failed, SaveUser failed\n    at %#x\n%s", pc, rawPCTrailer())
+		if actual := fmt.Sprintf("%+v", err); actual != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, actual)
+		}
+	})
+
+	t.Run("Print raw program counters with every formatter", func(t *testing.T) {
+		compact := fmt.Sprintf("ErrUpdateOneFailed: SaveUser failed [%#x] (%s)", pc, rawPCTrailer())
+		if actual := Render(err, CompactFormatter{}, true); actual != compact {
+			t.Errorf("Expected '%s', got '%s'", compact, actual)
+		}
+		logfmt := fmt.Sprintf("err.0.pc=%#x err.build=%s err.anchor=%#x", pc, logfmt.Value(BuildID()), rawPCAnchorAddr())
+		if actual := Render(err, LogfmtFormatter{}, true); !strings.HasSuffix(actual, logfmt) || strings.Contains(actual, "err.0.func=") {
+			t.Errorf("Expected '%s' at the end, got '%s'", logfmt, actual)
+		}
+	})
+
+	t.Run("Encode raw program counters in JSON", func(t *testing.T) {
+		data, _ := json.Marshal(err)
+		if !strings.Contains(string(data), fmt.Sprintf(`"frame":{"pc":"%#x"}`, pc)) ||
+			!strings.Contains(string(data), fmt.Sprintf(`"anchor":"%#x"`, rawPCAnchorAddr())) {
+			t.Errorf("Expected the raw program counter and the anchor, got '%s'", data)
+		}
+	})
+
+	t.Run("Build ID", func(t *testing.T) {
+		if runtime.GOOS == "linux" && BuildID() == "" {
+			t.Error("Expected the build ID of the test binary")
+		}
+	})
+}
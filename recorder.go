@@ -0,0 +1,230 @@
+package ppcerrors
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// RecordedError is an error recorded by a Recorder.
+	RecordedError struct {
+		Time        time.Time `json:"time"`
+		Fingerprint string    `json:"fingerprint"`
+		Error       string    `json:"error"`
+		// Verbose is the %+v rendering of the error.
+		Verbose string `json:"verbose"`
+		// Definitions holds the names of the definitions in the chain, and of their ancestors.
+		Definitions []string `json:"definitions,omitempty"`
+		// ErrorCodes holds the names of the error codes in the chain, and of their ancestors.
+		ErrorCodes []string `json:"errorCodes,omitempty"`
+		// Codes holds the codes of the error codes in the chain, and of their ancestors.
+		Codes []int `json:"codes,omitempty"`
+
+		// err is the recorded error, which the other fields are computed from when the recorded errors are read.
+		err error
+	}
+
+	// Recorder keeps the last errors recorded in a bounded ring buffer, so that the recent errors of a server can be inspected
+	// during an incident without shipping logs. Nothing is recorded until errors are passed to Record, e.g.:
+	//
+	//	recorder := ppcerrors.NewRecorder(200)
+	//	recorder.RecordCreated()
+	//	http.Handle("/debug/errors", recorder)
+	//
+	// A Recorder is also a Sink, so that it can record the errors sent to a Reporter instead of every error created.
+	// Recording only keeps the error and the time, the errors are rendered when they are read by Errors or ServeHTTP,
+	// which keeps them, and the values of their fields, in memory until they are dropped from the buffer.
+	// It is safe for concurrent use.
+	Recorder struct {
+		mu    sync.Mutex
+		buf   []RecordedError
+		next  int
+		total int
+	}
+)
+
+// NewRecorder creates a Recorder keeping the last size errors, size defaults to 100 when it is not positive.
+func NewRecorder(size int) *Recorder {
+	if size <= 0 {
+		size = 100
+	}
+	return &Recorder{buf: make([]RecordedError, 0, size)}
+}
+
+// Record records err, the oldest error is dropped when the buffer is full. Record does nothing when err is nil.
+func (r *Recorder) Record(err error) {
+	if err == nil {
+		return
+	}
+
+	re := RecordedError{Time: time.Now(), err: err}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.buf) < cap(r.buf) {
+		r.buf = append(r.buf, re)
+	} else {
+		r.buf[r.next] = re
+	}
+	r.next = (r.next + 1) % cap(r.buf)
+	r.total++
+}
+
+// Report implements Sink by recording the error of each report, so that a Recorder can record the errors sent to a Reporter.
+func (r *Recorder) Report(report Report) {
+	r.Record(report.Err)
+}
+
+// RecordCreated records the errors created from now on that start a new chain, see OnCreate, and returns a function
+// to stop recording. An error starts a new chain when it is created by New, or wraps an error that has no layer created
+// by this package, so that an error wrapped several times fills a single slot, with the layers it had when it was created.
+// Record the errors where they are handled, or send them to a Reporter, to keep their outer layers.
+// Each error created costs a walk of the chain it wraps, see Record for the cost of recording.
+func (r *Recorder) RecordCreated() (stop func()) {
+	return OnCreate(func(e Event) {
+		if e.Cause == nil || !hasBuiltin(e.Cause) {
+			r.Record(e.Err)
+		}
+	})
+}
+
+// hasBuiltin reports whether a layer in err's chain is created by this package.
+func hasBuiltin(err error) bool {
+	found := false
+	walkErrors(err, func(e error) bool {
+		found = isBuiltin(e)
+		return !found
+	})
+	return found
+}
+
+// Errors returns the recorded errors, from the newest to the oldest.
+func (r *Recorder) Errors() []RecordedError {
+	r.mu.Lock()
+	errs := make([]RecordedError, 0, len(r.buf))
+	for i := 0; i < len(r.buf); i++ {
+		errs = append(errs, r.buf[(r.next-1-i+2*len(r.buf))%len(r.buf)])
+	}
+	r.mu.Unlock()
+
+	for i := range errs {
+		errs[i] = resolveRecorded(errs[i])
+	}
+	return errs
+}
+
+// resolveRecorded returns re with the fields computed from the recorded error.
+func resolveRecorded(re RecordedError) RecordedError {
+	err := re.err
+	re.Fingerprint = Fingerprint(err)
+	re.Error = err.Error()
+	re.Verbose = fmt.Sprintf("%+v", err)
+	walkErrors(err, func(e error) bool {
+		eachDefinition(definitionOf(e), func(d Definer) bool {
+			re.Definitions = append(re.Definitions, d.Name())
+			return true
+		})
+		eachErrorCode(errorCodeOf(e), func(c ErrorCoder) bool {
+			re.ErrorCodes = append(re.ErrorCodes, c.Name())
+			re.Codes = append(re.Codes, c.Code())
+			return true
+		})
+		return true
+	})
+	return re
+}
+
+// Total returns the number of errors recorded since r was created, including the dropped ones.
+func (r *Recorder) Total() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.total
+}
+
+// ServeHTTP lists the recorded errors, from the newest to the oldest, as an HTML page,
+// or as JSON when the format query parameter is json or the Accept header asks for application/json.
+// The following query parameters filter the errors:
+//   - def: the name of a definition in the chain, including the categories, e.g.: def=ErrDB.
+//   - code: the name or the code of an error code in the chain, e.g.: code=500 or code=ErrInternalServerError.
+//   - limit: the maximum number of errors listed.
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	def, code := query.Get("def"), query.Get("code")
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	var errs []RecordedError
+	for _, re := range r.Errors() {
+		if def != "" && !containsString(re.Definitions, def) {
+			continue
+		}
+		if code != "" && !containsString(re.ErrorCodes, code) && !containsCode(re.Codes, code) {
+			continue
+		}
+		errs = append(errs, re)
+		if limit > 0 && len(errs) == limit {
+			break
+		}
+	}
+
+	if query.Get("format") == "json" || strings.Contains(req.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		if errs == nil {
+			errs = []RecordedError{}
+		}
+		_ = json.NewEncoder(w).Encode(errs)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = recorderPage.Execute(w, struct {
+		Def    string
+		Code   string
+		Total  int
+		Errors []RecordedError
+	}{def, code, r.Total(), errs})
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsCode(codes []int, value string) bool {
+	code, err := strconv.Atoi(value)
+	if err != nil {
+		return false
+	}
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+var recorderPage = template.Must(template.New("recorder").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Recent errors</title></head>
+<body>
+<h1>Recent errors</h1>
+<form>
+Definition: <input name="def" value="{{.Def}}">
+Error code: <input name="code" value="{{.Code}}">
+<input type="submit" value="Filter"> <a href="?format=json&amp;def={{.Def}}&amp;code={{.Code}}">JSON</a>
+</form>
+<p>{{len .Errors}} errors listed, {{.Total}} recorded in total.</p>
+{{range .Errors}}<h3>{{.Time.Format "2006-01-02T15:04:05.000Z07:00"}} {{.Fingerprint}}</h3>
+<pre>{{.Verbose}}</pre>
+{{end}}</body>
+</html>
+`))
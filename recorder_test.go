@@ -0,0 +1,110 @@
+package ppcerrors
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	errDB := NewDefinition("ErrDB", "Database error")
+	errUpdateOneFailed := errDB.NewChild("ErrUpdateOneFailed", "db.UpdateOne failed")
+	errInternalServerError := NewErrorCode("ErrInternalServerError", 500, "Internal server error")
+
+	t.Run("Keep the last errors", func(t *testing.T) {
+		r := NewRecorder(2)
+		r.Record(errors.New("1"))
+		r.Record(errors.New("2"))
+		r.Record(errors.New("3"))
+		r.Record(nil)
+
+		errs := r.Errors()
+		if len(errs) != 2 || errs[0].Error != "3" || errs[1].Error != "2" || r.Total() != 3 {
+			t.Errorf("Expected the errors 3 and 2 out of 3, got %v", errs)
+		}
+	})
+
+	t.Run("Record created errors", func(t *testing.T) {
+		r := NewRecorder(10)
+		stop := r.RecordCreated()
+		_ = errUpdateOneFailed.New("SaveUser failed")
+		stop()
+		_ = errUpdateOneFailed.New("SaveUser failed")
+
+		errs := r.Errors()
+		if len(errs) != 1 || errs[0].Verbose != "ErrUpdateOneFailed, db.UpdateOne failed, SaveUser failed" || errs[0].Fingerprint == "" {
+			t.Errorf("Expected the created error, got %v", errs)
+		}
+	})
+
+	t.Run("Record only the errors starting a new chain", func(t *testing.T) {
+		r := NewRecorder(10)
+		stop := r.RecordCreated()
+		err := errUpdateOneFailed.Wrap(errors.New("mock mongodb error"), "SaveUser failed")
+		err = WithFields(Wrap(errInternalServerError.Wrap(err, "Login failed"), "retrying"), F("uid", 123))
+		stop()
+
+		errs := r.Errors()
+		if len(errs) != 1 || errs[0].Error != "ErrUpdateOneFailed, db.UpdateOne failed, SaveUser failed <= mock mongodb error" || r.Total() != 1 {
+			t.Errorf("Expected a single error for the chain, got %v", errs)
+		}
+	})
+
+	t.Run("Record reported errors", func(t *testing.T) {
+		r := NewRecorder(10)
+		reporter := NewReporter(ReporterConfig{Sink: r})
+		reporter.Report(errUpdateOneFailed.New())
+		reporter.Close()
+		if len(r.Errors()) != 1 {
+			t.Errorf("Expected the reported error, got %v", r.Errors())
+		}
+	})
+
+	r := NewRecorder(10)
+	r.Record(errInternalServerError.Wrap(errUpdateOneFailed.New("SaveUser failed"), "Login failed"))
+	r.Record(errors.New("mock mongodb error"))
+
+	serve := func(url string) []RecordedError {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		var errs []RecordedError
+		if err := json.Unmarshal(w.Body.Bytes(), &errs); err != nil {
+			t.Fatalf("Expected JSON, got '%s'", w.Body.String())
+		}
+		return errs
+	}
+
+	t.Run("Filter by definition and code", func(t *testing.T) {
+		if errs := serve("/debug/errors?format=json"); len(errs) != 2 {
+			t.Errorf("Expected 2 errors, got %d", len(errs))
+		}
+		if errs := serve("/debug/errors?format=json&def=ErrDB"); len(errs) != 1 || !strings.HasPrefix(errs[0].Error, "ErrInternalServerError") {
+			t.Errorf("Expected the error of the category ErrDB, got %v", errs)
+		}
+		if errs := serve("/debug/errors?format=json&code=500"); len(errs) != 1 {
+			t.Errorf("Expected the error with the code 500, got %v", errs)
+		}
+		if errs := serve("/debug/errors?format=json&code=ErrInternalServerError&def=ErrNotFound"); len(errs) != 0 {
+			t.Errorf("Expected no error, got %v", errs)
+		}
+		if errs := serve("/debug/errors?format=json&limit=1"); len(errs) != 1 || errs[0].Error != "mock mongodb error" {
+			t.Errorf("Expected the newest error, got %v", errs)
+		}
+	})
+
+	t.Run("Serve HTML", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/debug/errors", nil))
+		if body := w.Body.String(); !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") || !strings.Contains(body, "<pre>mock mongodb error</pre>") {
+			t.Errorf("Expected an HTML page listing the errors, got '%s'", body)
+		}
+
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/debug/errors?def=<script>", nil))
+		if strings.Contains(w.Body.String(), "<script>") {
+			t.Error("Expected the filters to be escaped")
+		}
+	})
+}
@@ -0,0 +1,265 @@
+package ppcerrors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type (
+	// Report is what a Reporter sends to its Sink: either the first occurrence of an error,
+	// or the summary of the occurrences of an error in the last interval.
+	Report struct {
+		// Fingerprint of the errors, see Fingerprint, "" for the summary of the errors that did not fit in ReporterConfig.MaxGroups.
+		Fingerprint string
+		// Err is the first occurrence, or the last occurrence for a summary.
+		Err error
+		// First tells whether the report is the first occurrence of the error.
+		First bool
+		// Count is the number of occurrences summarized, 1 for the first occurrence.
+		Count int
+		// Window is the time the summary covers, 0 for the first occurrence.
+		Window time.Duration
+	}
+
+	// Sink receives the reports of a Reporter, Report is never called concurrently by the same Reporter.
+	Sink interface {
+		Report(r Report)
+	}
+
+	// SinkFunc adapts a function to a Sink.
+	SinkFunc func(r Report)
+
+	// ReporterConfig controls how a Reporter groups errors and reports them.
+	// Zero values are replaced by the defaults: an interval of 10s, 1000 groups, and a WriterSink to os.Stderr.
+	ReporterConfig struct {
+		// Interval between two summaries.
+		Interval time.Duration
+		// MaxGroups bounds the number of fingerprints remembered, the errors that do not fit are only counted.
+		MaxGroups int
+		// Sink the reports are sent to.
+		Sink Sink
+		// FingerprintOptions customize how errors are grouped, see Fingerprint.
+		FingerprintOptions []FingerprintOption
+	}
+
+	// Reporter deduplicates errors by fingerprint to protect logs and alerting from floods of identical errors:
+	// the first occurrence of an error is reported immediately, and the next ones are summarized every interval, e.g.:
+	//
+	//	ErrUpdateOneFailed x 3521 in last 10s
+	//
+	// A fingerprint that has no occurrence in an interval is forgotten, so that its next occurrence is reported immediately again.
+	// It is safe for concurrent use.
+	Reporter struct {
+		config   ReporterConfig
+		sinkMu   sync.Mutex
+		mu       sync.Mutex
+		groups   map[string]*reportGroup
+		overflow reportGroup
+		since    time.Time
+		done     chan struct{}
+		stopOnce sync.Once
+		stopped  sync.WaitGroup
+	}
+
+	// reportGroup counts the occurrences of a fingerprint in the current interval after the first one,
+	// active tells whether the fingerprint occurred in the current interval, including the first occurrence.
+	reportGroup struct {
+		count  int
+		last   error
+		active bool
+	}
+
+	writerSink struct {
+		w io.Writer
+	}
+
+	slogSink struct {
+		logger *slog.Logger
+	}
+)
+
+// Report implements Sink.
+func (f SinkFunc) Report(r Report) {
+	f(r)
+}
+
+// String describes r in a single line, e.g.: "ErrUpdateOneFailed x 3521 in last 10s" for a summary.
+func (r Report) String() string {
+	if r.First {
+		return r.Err.Error()
+	}
+	name := reportName(r.Err)
+	if r.Fingerprint == "" {
+		name = "errors over the group limit"
+	}
+	return name + " x " + strconv.Itoa(r.Count) + " in last " + r.Window.Round(time.Millisecond).String()
+}
+
+// reportName returns the name of the outermost definition or error code of err, or the text of err when it has none.
+func reportName(err error) string {
+	name := outermostName(err)
+	if name == "" && err != nil {
+		return err.Error()
+	}
+	return name
+}
+
+// outermostName returns the name of the outermost definition or error code of err, or "" when it has none.
+func outermostName(err error) string {
+	name := ""
+	Walk(err, func(l Layer) bool {
+		switch {
+		case l.ErrorCode != nil:
+			name = errorCodeName(l.ErrorCode)
+		case l.Definition != nil:
+			name = definitionName(l.Definition)
+		}
+		return name == ""
+	})
+	return name
+}
+
+// WriterSink creates a Sink writing each report to w in a line, see Report.String.
+func WriterSink(w io.Writer) Sink {
+	return &writerSink{w: w}
+}
+
+func (s *writerSink) Report(r Report) {
+	_, _ = fmt.Fprintln(s.w, r.String())
+}
+
+// SlogSink creates a Sink logging reports to logger at the level of the severity of their error, see SeverityOf.
+// The first occurrences are logged with the message "error" and the attributes error and fingerprint,
+// and the summaries with the message "error summary" and the attributes name, fingerprint, count, and window.
+func SlogSink(logger *slog.Logger) Sink {
+	return &slogSink{logger: logger}
+}
+
+func (s *slogSink) Report(r Report) {
+	level := SeverityOf(r.Err).Level()
+	if r.First {
+		s.logger.Log(context.Background(), level, "error", slog.Any("error", r.Err), slog.String("fingerprint", r.Fingerprint))
+		return
+	}
+	s.logger.Log(context.Background(), level, "error summary",
+		slog.String("name", reportName(r.Err)), slog.String("fingerprint", r.Fingerprint),
+		slog.Int("count", r.Count), slog.Duration("window", r.Window))
+}
+
+// NewReporter creates a Reporter and starts sending summaries every config.Interval, until Close is called.
+func NewReporter(config ReporterConfig) *Reporter {
+	if config.Interval <= 0 {
+		config.Interval = 10 * time.Second
+	}
+	if config.MaxGroups <= 0 {
+		config.MaxGroups = 1000
+	}
+	if config.Sink == nil {
+		config.Sink = WriterSink(os.Stderr)
+	}
+
+	r := &Reporter{
+		config: config,
+		groups: make(map[string]*reportGroup),
+		since:  time.Now(),
+		done:   make(chan struct{}),
+	}
+	r.stopped.Add(1)
+	go r.loop()
+	return r
+}
+
+func (r *Reporter) loop() {
+	defer r.stopped.Done()
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.Flush()
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// Report records an occurrence of err, it is sent to the Sink immediately when it is the first occurrence of its fingerprint.
+// Report does nothing when err is nil.
+func (r *Reporter) Report(err error) {
+	if err == nil {
+		return
+	}
+	fingerprint := Fingerprint(err, r.config.FingerprintOptions...)
+
+	r.mu.Lock()
+	if g, ok := r.groups[fingerprint]; ok {
+		g.count++
+		g.last = err
+		g.active = true
+		r.mu.Unlock()
+		return
+	}
+	if len(r.groups) >= r.config.MaxGroups {
+		r.overflow.count++
+		r.overflow.last = err
+		r.mu.Unlock()
+		return
+	}
+	r.groups[fingerprint] = &reportGroup{active: true}
+	r.mu.Unlock()
+
+	r.send(Report{Fingerprint: fingerprint, Err: err, First: true, Count: 1})
+}
+
+// Flush sends the summaries of the current interval immediately, from the most frequent error, and starts a new interval.
+func (r *Reporter) Flush() {
+	now := time.Now()
+
+	r.mu.Lock()
+	window := now.Sub(r.since)
+	r.since = now
+	var reports []Report
+	for fingerprint, g := range r.groups {
+		if !g.active {
+			delete(r.groups, fingerprint)
+			continue
+		}
+		if g.count > 0 {
+			reports = append(reports, Report{Fingerprint: fingerprint, Err: g.last, Count: g.count, Window: window})
+		}
+		g.count, g.last, g.active = 0, nil, false
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Count > reports[j].Count })
+	if r.overflow.count > 0 {
+		reports = append(reports, Report{Err: r.overflow.last, Count: r.overflow.count, Window: window})
+		r.overflow = reportGroup{}
+	}
+	r.mu.Unlock()
+
+	for _, report := range reports {
+		r.send(report)
+	}
+}
+
+// Close stops the summaries and sends the last ones. Report must not be called after Close.
+func (r *Reporter) Close() {
+	r.stopOnce.Do(func() {
+		close(r.done)
+		r.stopped.Wait()
+		r.Flush()
+	})
+}
+
+// send sends report to the Sink, one report at a time.
+func (r *Reporter) send(report Report) {
+	r.sinkMu.Lock()
+	defer r.sinkMu.Unlock()
+	r.config.Sink.Report(report)
+}
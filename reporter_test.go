@@ -0,0 +1,168 @@
+package ppcerrors
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// reportRecorder is a Sink recording the reports it receives.
+type reportRecorder struct {
+	mu      sync.Mutex
+	reports []Report
+}
+
+func (s *reportRecorder) Report(r Report) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reports = append(s.reports, r)
+}
+
+func (s *reportRecorder) take() []Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reports := s.reports
+	s.reports = nil
+	return reports
+}
+
+func TestReporter(t *testing.T) {
+	errUpdateOneFailed := NewDefinition("ErrUpdateOneFailed", "db.UpdateOne failed")
+	errRedisGet := NewDefinition("ErrRedisGet", "redis.Get failed")
+
+	t.Run("Report the first occurrence and summarize the next ones", func(t *testing.T) {
+		sink := &reportRecorder{}
+		r := NewReporter(ReporterConfig{Interval: time.Hour, Sink: sink})
+		defer r.Close()
+
+		for i := 0; i < 5; i++ {
+			r.Report(WithFields(errUpdateOneFailed.New("SaveUser failed"), F("uid", i)))
+		}
+		r.Report(errRedisGet.New())
+
+		reports := sink.take()
+		if len(reports) != 2 || !reports[0].First || reports[0].Count != 1 || !reports[1].First {
+			t.Fatalf("Expected 2 first occurrences, got %v", reports)
+		}
+
+		r.Flush()
+		reports = sink.take()
+		if len(reports) != 1 || reports[0].First || reports[0].Count != 4 {
+			t.Fatalf("Expected a summary of 4 errors, got %v", reports)
+		}
+		if s := reports[0].String(); !strings.HasPrefix(s, "ErrUpdateOneFailed x 4 in last ") {
+			t.Errorf("Expected the summary 'ErrUpdateOneFailed x 4 in last ...', got '%s'", s)
+		}
+	})
+
+	t.Run("Report again after a quiet interval", func(t *testing.T) {
+		sink := &reportRecorder{}
+		r := NewReporter(ReporterConfig{Interval: time.Hour, Sink: sink})
+		defer r.Close()
+
+		r.Report(errUpdateOneFailed.New())
+		r.Flush()
+		r.Report(errUpdateOneFailed.New())
+		if reports := sink.take(); len(reports) != 1 {
+			t.Fatalf("Expected the second error to be counted, got %v", reports)
+		}
+		r.Flush()
+		r.Flush()
+		r.Report(errUpdateOneFailed.New())
+		if reports := sink.take(); len(reports) != 2 || !reports[1].First {
+			t.Errorf("Expected a summary and a new first occurrence, got %v", reports)
+		}
+	})
+
+	t.Run("Bound the number of groups", func(t *testing.T) {
+		sink := &reportRecorder{}
+		r := NewReporter(ReporterConfig{Interval: time.Hour, MaxGroups: 1, Sink: sink})
+		defer r.Close()
+
+		r.Report(errUpdateOneFailed.New())
+		r.Report(errRedisGet.New())
+		r.Report(errors.New("mock mongodb error"))
+		r.Flush()
+		reports := sink.take()
+		if len(reports) != 2 || reports[1].Fingerprint != "" || reports[1].Count != 2 {
+			t.Fatalf("Expected the errors over the limit to be counted, got %v", reports)
+		}
+		if s := reports[1].String(); !strings.HasPrefix(s, "errors over the group limit x 2") {
+			t.Errorf("Expected the overflow summary, got '%s'", s)
+		}
+	})
+
+	t.Run("Send the last summaries on Close", func(t *testing.T) {
+		sink := &reportRecorder{}
+		r := NewReporter(ReporterConfig{Interval: time.Hour, Sink: sink})
+		r.Report(errUpdateOneFailed.New())
+		r.Report(errUpdateOneFailed.New())
+		r.Close()
+		r.Close()
+		if reports := sink.take(); len(reports) != 2 || reports[1].Count != 1 {
+			t.Errorf("Expected the summary to be sent on Close, got %v", reports)
+		}
+	})
+
+	t.Run("Summarize periodically", func(t *testing.T) {
+		sink := &reportRecorder{}
+		r := NewReporter(ReporterConfig{Interval: 10 * time.Millisecond, Sink: sink})
+		r.Report(errUpdateOneFailed.New())
+		r.Report(errUpdateOneFailed.New())
+		time.Sleep(50 * time.Millisecond)
+		r.Report(errUpdateOneFailed.New())
+		r.Close()
+		r.Close()
+
+		reports := sink.take()
+		if len(reports) != 3 || !reports[0].First || reports[1].First || reports[1].Count != 1 || !reports[2].First {
+			t.Errorf("Expected a first occurrence, a periodic summary, and a first occurrence after the quiet interval, got %v", reports)
+		}
+	})
+}
+
+func TestSinks(t *testing.T) {
+	errUpdateOneFailed := NewDefinition("ErrUpdateOneFailed", "db.UpdateOne failed").MarkSeverity(SeverityWarn)
+	first := Report{Fingerprint: "0123456789abcdef", Err: errUpdateOneFailed.New(), First: true, Count: 1}
+	summary := Report{Fingerprint: "0123456789abcdef", Err: errUpdateOneFailed.New(), Count: 3521, Window: 10 * time.Second}
+
+	t.Run("WriterSink", func(t *testing.T) {
+		var b bytes.Buffer
+		sink := WriterSink(&b)
+		sink.Report(first)
+		sink.Report(summary)
+		expected := "ErrUpdateOneFailed, db.UpdateOne failed\nErrUpdateOneFailed x 3521 in last 10s\n"
+		if b.String() != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, b.String())
+		}
+	})
+
+	t.Run("SlogSink", func(t *testing.T) {
+		var b bytes.Buffer
+		sink := SlogSink(slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return a
+			},
+		})))
+		sink.Report(summary)
+		expected := "level=WARN msg=\"error summary\" name=ErrUpdateOneFailed fingerprint=0123456789abcdef count=3521 window=10s\n"
+		if b.String() != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, b.String())
+		}
+	})
+
+	t.Run("SinkFunc", func(t *testing.T) {
+		var got Report
+		SinkFunc(func(r Report) { got = r }).Report(first)
+		if got.Fingerprint != first.Fingerprint {
+			t.Error("Expected the report to be passed to the function")
+		}
+	})
+}
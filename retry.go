@@ -0,0 +1,205 @@
+package ppcerrors
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// Retryability tells whether an error is transient so that the failed operation can be retried.
+	Retryability int
+
+	// RetryPolicy controls how Retry calls the operation again.
+	// Zero values are replaced by the defaults: 3 attempts, 100ms initial backoff, a multiplier of 2 and no maximum backoff.
+	RetryPolicy struct {
+		// MaxAttempts is the maximum number of calls to the operation, including the first one.
+		MaxAttempts int
+		// InitialBackoff is the time to wait before the second attempt.
+		InitialBackoff time.Duration
+		// MaxBackoff caps the time to wait between two attempts, 0 means no cap.
+		MaxBackoff time.Duration
+		// Multiplier is the factor the backoff grows by after each attempt.
+		Multiplier float64
+		// RetryUnclassified retries the errors whose retryability no layer decides, e.g.: foreign errors,
+		// which are not retried by default, see IsRetryable.
+		RetryUnclassified bool
+	}
+)
+
+const (
+	// RetryUnspecified means the layer does not decide the retryability, the decision is left to the inner layers.
+	RetryUnspecified Retryability = iota
+	// RetryAllowed means the error is transient and the failed operation can be retried.
+	RetryAllowed
+	// RetryForbidden means the error is permanent and retrying the failed operation is useless.
+	RetryForbidden
+)
+
+func (r Retryability) String() string {
+	switch r {
+	case RetryAllowed:
+		return "retryable"
+	case RetryForbidden:
+		return "permanent"
+	default:
+		return "unspecified"
+	}
+}
+
+// WithRetryable overrides the retryability of the outermost layer of err and returns the new error,
+// regardless of the retryability of its definition or error code. See WithFields for how the outermost layer is copied.
+// WithRetryable returns nil when err is nil.
+func WithRetryable(err error, retryable bool) error {
+	if err == nil {
+		return nil
+	}
+	return amend(err, func(a *attrs) {
+		if retryable {
+			a.retry = RetryAllowed
+		} else {
+			a.retry = RetryForbidden
+		}
+	})
+}
+
+// IsRetryable reports whether err is transient according to the first layer in err's chain,
+// from the outermost to the innermost, that decides the retryability. Each layer is resolved in the following order:
+//  1. The override set by WithRetryable.
+//  2. The retryability of the layer's error code or definition, inherited from their parents when unspecified.
+//  3. The Timeout() bool and Temporary() bool methods of errors not created by this package,
+//     e.g.: net.Error and context.DeadlineExceeded, which only decide the error is transient when returning true.
+//
+// IsRetryable returns false when no layer decides the retryability, or err is nil.
+func IsRetryable(err error) bool {
+	return retryability(err) == RetryAllowed
+}
+
+// retryability returns the retryability decided by the first layer in err's chain that decides it, see IsRetryable,
+// or RetryUnspecified when no layer decides it.
+func retryability(err error) Retryability {
+	decision := RetryUnspecified
+	Walk(err, func(l Layer) bool {
+		decision = retryabilityOf(l)
+		return decision == RetryUnspecified
+	})
+	return decision
+}
+
+// retryabilityOf resolves the retryability decided by the layer l, see IsRetryable.
+func retryabilityOf(l Layer) Retryability {
+	if l.Retryability != RetryUnspecified {
+		return l.Retryability
+	}
+
+	decision := RetryUnspecified
+	eachErrorCode(l.ErrorCode, func(c ErrorCoder) bool {
+		if r, ok := c.(interface{ Retryability() Retryability }); ok {
+			decision = r.Retryability()
+		}
+		return decision == RetryUnspecified
+	})
+	eachDefinition(l.Definition, func(d Definer) bool {
+		if r, ok := d.(interface{ Retryability() Retryability }); ok {
+			decision = r.Retryability()
+		}
+		return decision == RetryUnspecified
+	})
+	if decision != RetryUnspecified {
+		return decision
+	}
+
+	if l.Kind == ForeignLayer {
+		if t, ok := l.Err.(interface{ Timeout() bool }); ok && t.Timeout() {
+			return RetryAllowed
+		}
+		if t, ok := l.Err.(interface{ Temporary() bool }); ok && t.Temporary() {
+			return RetryAllowed
+		}
+	}
+
+	return RetryUnspecified
+}
+
+// Retry calls fn until it succeeds, returns an error that is not retryable according to IsRetryable,
+// the attempts allowed by policy are used up, or ctx is done. It waits between two attempts with an exponential backoff.
+// Errors whose retryability no layer decides are only retried when policy.RetryUnclassified is true.
+//
+// Retry returns nil when fn succeeds, otherwise it wraps the last error returned by fn with the message "retry stopped"
+// and the fields attempts (the number of calls to fn) and reason, e.g.:
+// retry stopped, attempts=3, reason=exhausted <= ErrRedisGet, redis.Get failed. The reason is one of:
+//   - permanent: a layer decides the error is permanent, see RetryForbidden.
+//   - not retryable: no layer decides the retryability of the error and policy.RetryUnclassified is false.
+//   - exhausted: the attempts allowed by policy are used up.
+//   - the error of ctx, e.g.: context canceled.
+//
+// When Config.Caller == true, the caller of Retry is recorded.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	policy = policy.withDefaults()
+	backoff := policy.InitialBackoff
+
+	var (
+		err    error
+		reason string
+	)
+	attempts := 0
+	for {
+		attempts++
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+		switch retryability(err) {
+		case RetryForbidden:
+			reason = "permanent"
+		case RetryUnspecified:
+			if !policy.RetryUnclassified {
+				reason = "not retryable"
+			}
+		}
+		if reason != "" {
+			break
+		}
+		if attempts >= policy.MaxAttempts {
+			reason = "exhausted"
+			break
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			reason = ctx.Err().Error()
+		case <-timer.C:
+		}
+		if reason != "" {
+			break
+		}
+
+		backoff = time.Duration(float64(backoff) * policy.Multiplier)
+		if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+
+	return created(&withCause{
+		error: &withMessage{
+			msg:   "retry stopped",
+			pc:    getPCFromCaller(),
+			attrs: newAttrs(err).withFields(F("attempts", attempts), F("reason", reason)),
+		},
+		cause: err,
+	}, err)
+}
+
+// withDefaults returns a copy of p whose zero values are replaced by the defaults.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = 100 * time.Millisecond
+	}
+	if p.Multiplier <= 0 {
+		p.Multiplier = 2
+	}
+	return p
+}
@@ -0,0 +1,141 @@
+package ppcerrors
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// timeoutError mocks a net.Error returned by a network operation.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	errRedis := NewDefinition("ErrRedis", "Redis error").MarkRetryable()
+	errRedisGet := errRedis.NewChild("ErrRedisGet", "redis.Get failed")
+	errInvalidArgument := NewErrorCode("ErrInvalidArgument", 400, "Invalid argument").MarkPermanent()
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"Retryable definition", errRedis.New(), true},
+		{"Inherited from the parent definition", Wrap(errRedisGet.New(), "outer"), true},
+		{"Permanent error code wins as the outer layer", errInvalidArgument.Wrap(errRedis.New()), false},
+		{"Layer override", WithRetryable(errRedis.New(), false), false},
+		{"Layer override of a foreign error", WithRetryable(errors.New("foreign"), true), true},
+		{"Foreign timeout error", Wrap(timeoutError{}, "dial"), true},
+		{"Context deadline exceeded", Wrap(context.DeadlineExceeded, "query"), true},
+		{"Undecided error", errors.New("foreign"), false},
+		{"Nil error", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := IsRetryable(tt.err); actual != tt.expected {
+				t.Errorf("Expected IsRetryable to return %t, got %t", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	errTransient := NewDefinition("ErrTransient", "Transient error").MarkRetryable()
+	errPermanent := NewDefinition("ErrPermanent", "Permanent error").MarkPermanent()
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	t.Run("Succeed after retrying", func(t *testing.T) {
+		attempts := 0
+		err := Retry(context.Background(), policy, func(ctx context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return errTransient.New()
+			}
+			return nil
+		})
+		if err != nil || attempts != 3 {
+			t.Errorf("Expected success after 3 attempts, got %v after %d attempts", err, attempts)
+		}
+	})
+
+	t.Run("Stop on a permanent error", func(t *testing.T) {
+		attempts := 0
+		err := Retry(context.Background(), policy, func(ctx context.Context) error {
+			attempts++
+			return errPermanent.New()
+		})
+		if attempts != 1 {
+			t.Errorf("Expected 1 attempt, got %d", attempts)
+		}
+		expected := "retry stopped, attempts=1, reason=permanent <= ErrPermanent, Permanent error"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error message '%s', got '%v'", expected, err)
+		}
+		if !HasDefinition(err, errPermanent) {
+			t.Error("Expected the last error to be kept as the cause")
+		}
+	})
+
+	t.Run("Stop on an unclassified error", func(t *testing.T) {
+		attempts := 0
+		err := Retry(context.Background(), policy, func(ctx context.Context) error {
+			attempts++
+			return errors.New("mock mongodb error")
+		})
+		if attempts != 1 {
+			t.Errorf("Expected 1 attempt, got %d", attempts)
+		}
+		expected := "retry stopped, attempts=1, reason=not retryable <= mock mongodb error"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error message '%s', got '%v'", expected, err)
+		}
+	})
+
+	t.Run("Retry unclassified errors", func(t *testing.T) {
+		attempts := 0
+		p := policy
+		p.RetryUnclassified = true
+		err := Retry(context.Background(), p, func(ctx context.Context) error {
+			attempts++
+			return errors.New("mock mongodb error")
+		})
+		if attempts != 3 || err == nil || err.Error() != "retry stopped, attempts=3, reason=exhausted <= mock mongodb error" {
+			t.Errorf("Expected 3 attempts and the exhausted error, got %v after %d attempts", err, attempts)
+		}
+	})
+
+	t.Run("Stop when attempts are used up", func(t *testing.T) {
+		attempts := 0
+		err := Retry(context.Background(), policy, func(ctx context.Context) error {
+			attempts++
+			return errTransient.New()
+		})
+		if attempts != 3 {
+			t.Errorf("Expected 3 attempts, got %d", attempts)
+		}
+		if err == nil || err.Error() != "retry stopped, attempts=3, reason=exhausted <= ErrTransient, Transient error" {
+			t.Errorf("Expected the exhausted error, got '%v'", err)
+		}
+	})
+
+	t.Run("Stop when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		attempts := 0
+		err := Retry(ctx, RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Hour}, func(ctx context.Context) error {
+			attempts++
+			cancel()
+			return errTransient.New()
+		})
+		if attempts != 1 {
+			t.Errorf("Expected 1 attempt, got %d", attempts)
+		}
+		if err == nil || err.Error() != "retry stopped, attempts=1, reason=context canceled <= ErrTransient, Transient error" {
+			t.Errorf("Expected the canceled error, got '%v'", err)
+		}
+	})
+}
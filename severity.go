@@ -0,0 +1,139 @@
+package ppcerrors
+
+import "log/slog"
+
+// Severity tells how serious an error is, it helps log middlewares decide the log level of an error.
+type Severity int
+
+const (
+	// SeverityUnspecified means the layer does not decide the severity, the decision is left to the other layers.
+	SeverityUnspecified Severity = iota
+	SeverityDebug
+	SeverityInfo
+	SeverityWarn
+	SeverityError
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarn:
+		return "warn"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "unspecified"
+	}
+}
+
+// Level maps s to a slog.Level, SeverityCritical is mapped to slog.LevelError + 4,
+// and SeverityUnspecified is mapped to the level of Config.DefaultSeverity,
+// or to slog.LevelError when Config.DefaultSeverity is not a known severity either.
+func (s Severity) Level() slog.Level {
+	if level, ok := s.level(); ok {
+		return level
+	}
+	if level, ok := Config.DefaultSeverity.level(); ok {
+		return level
+	}
+	return slog.LevelError
+}
+
+// level maps s to a slog.Level, it returns false when s is SeverityUnspecified or not a known severity.
+func (s Severity) level() (slog.Level, bool) {
+	switch s {
+	case SeverityDebug:
+		return slog.LevelDebug, true
+	case SeverityInfo:
+		return slog.LevelInfo, true
+	case SeverityWarn:
+		return slog.LevelWarn, true
+	case SeverityError:
+		return slog.LevelError, true
+	case SeverityCritical:
+		return slog.LevelError + 4, true
+	}
+	return 0, false
+}
+
+// WithSeverity overrides the severity of the outermost layer of err and returns the new error,
+// regardless of the severity of its definition or error code. See WithFields for how the outermost layer is copied.
+// WithSeverity returns nil when err is nil.
+func WithSeverity(err error, severity Severity) error {
+	if err == nil {
+		return nil
+	}
+	return amend(err, func(a *attrs) {
+		a.severity = severity
+	})
+}
+
+// SeverityOf returns the severity decided by the outermost layer in err's chain that decides one,
+// so that an outer layer can raise or lower the severity of the errors it wraps. Each layer is resolved in the following order:
+//  1. The override set by WithSeverity.
+//  2. The severity of the layer's error code or definition, inherited from their parents when unspecified.
+//
+// SeverityOf returns Config.DefaultSeverity when no layer decides the severity, and SeverityUnspecified when err is nil.
+func SeverityOf(err error) Severity {
+	if err == nil {
+		return SeverityUnspecified
+	}
+
+	severity := SeverityUnspecified
+	Walk(err, func(l Layer) bool {
+		severity = severityOf(l)
+		return severity == SeverityUnspecified
+	})
+	if severity == SeverityUnspecified {
+		return Config.DefaultSeverity
+	}
+	return severity
+}
+
+// MaxSeverityOf returns the most severe value decided by any layer in err's chain, see SeverityOf for how each layer is resolved.
+// MaxSeverityOf returns Config.DefaultSeverity when no layer decides the severity, and SeverityUnspecified when err is nil.
+func MaxSeverityOf(err error) Severity {
+	if err == nil {
+		return SeverityUnspecified
+	}
+
+	severity := SeverityUnspecified
+	Walk(err, func(l Layer) bool {
+		if s := severityOf(l); s > severity {
+			severity = s
+		}
+		return true
+	})
+	if severity == SeverityUnspecified {
+		return Config.DefaultSeverity
+	}
+	return severity
+}
+
+// severityOf resolves the severity decided by the layer l, see SeverityOf.
+func severityOf(l Layer) Severity {
+	if l.Severity != SeverityUnspecified {
+		return l.Severity
+	}
+
+	severity := SeverityUnspecified
+	eachErrorCode(l.ErrorCode, func(c ErrorCoder) bool {
+		if s, ok := c.(interface{ Severity() Severity }); ok {
+			severity = s.Severity()
+		}
+		return severity == SeverityUnspecified
+	})
+	eachDefinition(l.Definition, func(d Definer) bool {
+		if s, ok := d.(interface{ Severity() Severity }); ok {
+			severity = s.Severity()
+		}
+		return severity == SeverityUnspecified
+	})
+	return severity
+}
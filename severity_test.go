@@ -0,0 +1,75 @@
+package ppcerrors
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestSeverityOf(t *testing.T) {
+	errNotFound := NewDefinition("ErrNotFound", "Not found").MarkSeverity(SeverityInfo)
+	errUserNotFound := errNotFound.NewChild("ErrUserNotFound", "User not found")
+	errDB := NewDefinition("ErrDB", "Database error").MarkSeverity(SeverityCritical)
+	errInternalServerError := NewErrorCode("ErrInternalServerError", 500, "Internal server error").MarkSeverity(SeverityError)
+
+	tests := []struct {
+		name        string
+		err         error
+		severity    Severity
+		maxSeverity Severity
+	}{
+		{"Definition", errNotFound.New(), SeverityInfo, SeverityInfo},
+		{"Inherited from the parent definition", Wrap(errUserNotFound.New(), "outer"), SeverityInfo, SeverityInfo},
+		{"Outermost layer wins", errInternalServerError.Wrap(errDB.New()), SeverityError, SeverityCritical},
+		{"Layer override", WithSeverity(errDB.New(), SeverityWarn), SeverityWarn, SeverityWarn},
+		{"Undecided error", errors.New("foreign"), SeverityError, SeverityError},
+		{"Nil error", nil, SeverityUnspecified, SeverityUnspecified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := SeverityOf(tt.err); actual != tt.severity {
+				t.Errorf("Expected SeverityOf to return %s, got %s", tt.severity, actual)
+			}
+			if actual := MaxSeverityOf(tt.err); actual != tt.maxSeverity {
+				t.Errorf("Expected MaxSeverityOf to return %s, got %s", tt.maxSeverity, actual)
+			}
+		})
+	}
+}
+
+func TestSeverityLevel(t *testing.T) {
+	tests := []struct {
+		severity Severity
+		level    slog.Level
+	}{
+		{SeverityDebug, slog.LevelDebug},
+		{SeverityInfo, slog.LevelInfo},
+		{SeverityWarn, slog.LevelWarn},
+		{SeverityError, slog.LevelError},
+		{SeverityCritical, slog.LevelError + 4},
+		{SeverityUnspecified, slog.LevelError},
+	}
+
+	for _, tt := range tests {
+		if actual := tt.severity.Level(); actual != tt.level {
+			t.Errorf("Expected %s to be mapped to %s, got %s", tt.severity, tt.level, actual)
+		}
+	}
+
+	t.Run("Default severity", func(t *testing.T) {
+		defer func() { Config.DefaultSeverity = SeverityError }()
+
+		Config.DefaultSeverity = SeverityWarn
+		if actual := SeverityUnspecified.Level(); actual != slog.LevelWarn {
+			t.Errorf("Expected %s, got %s", slog.LevelWarn, actual)
+		}
+		Config.DefaultSeverity = Severity(42)
+		if actual := SeverityUnspecified.Level(); actual != slog.LevelError {
+			t.Errorf("Expected %s for an unknown default severity, got %s", slog.LevelError, actual)
+		}
+		if actual := Severity(42).Level(); actual != slog.LevelError {
+			t.Errorf("Expected %s for an unknown severity, got %s", slog.LevelError, actual)
+		}
+	})
+}
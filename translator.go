@@ -0,0 +1,135 @@
+package ppcerrors
+
+import "strings"
+
+type (
+	// Rule maps the errors it matches to a public error code, see Translator.
+	Rule struct {
+		name     string
+		match    func(err error) bool
+		code     ErrorCoder
+		fallback bool
+	}
+
+	// Translator normalizes errors to public error codes at the boundaries of a system, e.g.: API handlers and RPC servers,
+	// so that different kinds of errors returned by internal functions are translated declaratively instead of by hand.
+	Translator struct {
+		rules    []Rule
+		fallback *Rule
+	}
+)
+
+// FromDefinition creates a Rule matching errors that contain the definition def or one of its descendants.
+func FromDefinition(def Definer, code ErrorCoder) Rule {
+	return Rule{
+		name:  def.Name() + " => " + code.Name(),
+		match: func(err error) bool { return HasDefinition(err, def) },
+		code:  code,
+	}
+}
+
+// FromErrorCode creates a Rule matching errors that contain the error code from or one of its descendants.
+func FromErrorCode(from ErrorCoder, code ErrorCoder) Rule {
+	return Rule{
+		name:  from.Name() + " => " + code.Name(),
+		match: func(err error) bool { return HasErrorCode(err, from) },
+		code:  code,
+	}
+}
+
+// When creates a Rule matching errors for which pred returns true, name is used to identify the rule.
+func When(name string, pred func(err error) bool, code ErrorCoder) Rule {
+	return Rule{
+		name:  name + " => " + code.Name(),
+		match: pred,
+		code:  code,
+	}
+}
+
+// Default creates a Rule matching any error, it only fires when no other rule of the Translator matches.
+func Default(code ErrorCoder) Rule {
+	return Rule{
+		name:     "default => " + code.Name(),
+		match:    func(error) bool { return true },
+		code:     code,
+		fallback: true,
+	}
+}
+
+// String returns the description of the rule, e.g.: ErrUpdateOneFailed => ErrInternalServerError.
+func (r Rule) String() string {
+	return r.name
+}
+
+// Code returns the error code the rule translates errors to.
+func (r Rule) Code() ErrorCoder {
+	return r.code
+}
+
+// NewTranslator creates a Translator that applies rules in the given order, the first matching rule fires.
+// A rule created by Default fires only when no other rule matches, regardless of its position.
+func NewTranslator(rules ...Rule) *Translator {
+	t := &Translator{}
+	for i := range rules {
+		if rules[i].fallback {
+			fallback := rules[i]
+			t.fallback = &fallback
+			continue
+		}
+		t.rules = append(t.rules, rules[i])
+	}
+	return t
+}
+
+// Translate wraps err with the error code of the first rule matching err and returns a copy of that rule along with the new error.
+// The messages parameter is joined with Config.MessagesSeparator as in ErrorCode.Wrap,
+// and when Config.Caller == true, the caller of Translate is recorded.
+//
+// The error code is added exactly once: when the outermost error code in err's chain is already the one of the matching rule,
+// err is returned unchanged. When no rule matches, err is returned unchanged with a nil rule.
+// Translate returns a nil rule and nil when err is nil.
+func (t *Translator) Translate(err error, messages ...string) (*Rule, error) {
+	if err == nil {
+		return nil, nil
+	}
+
+	var rule *Rule
+	for i := range t.rules {
+		if t.rules[i].match(err) {
+			matched := t.rules[i]
+			rule = &matched
+			break
+		}
+	}
+	if rule == nil && t.fallback != nil {
+		fallback := *t.fallback
+		rule = &fallback
+	}
+	if rule == nil {
+		return nil, err
+	}
+
+	if code := outermostErrorCode(err); code != nil && sameErrorCode(code, rule.code) {
+		return rule, err
+	}
+
+	return rule, created(&withCause{
+		error: &withErrorCode{
+			errCode: rule.code,
+			msg:     strings.Join(messages, Config.MessagesSeparator),
+			pc:      getPCFromCaller(),
+			attrs:   newAttrs(err),
+		},
+		cause: err,
+	}, err)
+}
+
+// outermostErrorCode returns the error code of the outermost ErrorCodeLayer in err's chain, or nil if there is none.
+func outermostErrorCode(err error) ErrorCoder {
+	var code ErrorCoder
+	Walk(err, func(l Layer) bool {
+		code = l.ErrorCode
+		return code == nil
+	})
+	return code
+}
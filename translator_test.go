@@ -0,0 +1,84 @@
+package ppcerrors
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTranslator(t *testing.T) {
+	errDB := NewDefinition("ErrDB", "Database error")
+	errUpdateOneFailed := errDB.NewChild("ErrUpdateOneFailed", "db.UpdateOne failed")
+	errTokenExpired := NewErrorCode("ErrTokenExpired", 10002, "Token expired")
+
+	errInternalServerError := NewErrorCode("ErrInternalServerError", 500, "Internal server error")
+	errUnauthorized := NewErrorCode("ErrUnauthorized", 401, "Unauthorized")
+	errTimeout := NewErrorCode("ErrTimeout", 504, "Timeout")
+
+	translator := NewTranslator(
+		Default(errInternalServerError),
+		FromDefinition(errDB, errInternalServerError),
+		FromErrorCode(errTokenExpired, errUnauthorized),
+		When("deadline exceeded", func(err error) bool { return errors.Is(err, context.DeadlineExceeded) }, errTimeout),
+	)
+
+	t.Run("Translate by rules in order", func(t *testing.T) {
+		tests := []struct {
+			err      error
+			code     ErrorCoder
+			ruleName string
+		}{
+			{errUpdateOneFailed.Wrap(errors.New("root")), errInternalServerError, "ErrDB => ErrInternalServerError"},
+			{errTokenExpired.New(), errUnauthorized, "ErrTokenExpired => ErrUnauthorized"},
+			{Wrap(context.DeadlineExceeded, "query"), errTimeout, "deadline exceeded => ErrTimeout"},
+			{errors.New("unknown"), errInternalServerError, "default => ErrInternalServerError"},
+		}
+
+		for _, tt := range tests {
+			rule, err := translator.Translate(tt.err, "Login failed")
+			if rule == nil || rule.String() != tt.ruleName {
+				t.Errorf("Expected rule '%s' to fire for '%v', got %v", tt.ruleName, tt.err, rule)
+				continue
+			}
+			if outermostErrorCode(err) != tt.code || rule.Code() != tt.code {
+				t.Errorf("Expected '%v' to be translated to %s", tt.err, tt.code.Name())
+			}
+			if !errors.Is(err, Root(tt.err)) {
+				t.Error("Expected the original error to be kept as the cause")
+			}
+		}
+	})
+
+	t.Run("Wrap with the public error code exactly once", func(t *testing.T) {
+		_, err := translator.Translate(errUpdateOneFailed.New())
+		rule, translated := translator.Translate(err)
+		if translated != err {
+			t.Error("Expected an already translated error to be returned unchanged")
+		}
+		if rule == nil || rule.Code() != errInternalServerError {
+			t.Error("Expected the matching rule to be reported")
+		}
+	})
+
+	t.Run("Return a copy of the rule", func(t *testing.T) {
+		rule, _ := translator.Translate(errTokenExpired.New())
+		*rule = Default(errTimeout)
+		if rule, _ := translator.Translate(errTokenExpired.New()); rule.Code() != errUnauthorized {
+			t.Errorf("Expected the translator to be unchanged, got %v", rule)
+		}
+	})
+
+	t.Run("No matching rule", func(t *testing.T) {
+		err := errors.New("unknown")
+		rule, translated := NewTranslator(FromDefinition(errDB, errInternalServerError)).Translate(err)
+		if translated != err || rule != nil {
+			t.Error("Expected the error to be returned unchanged with a nil rule")
+		}
+	})
+
+	t.Run("Nil error", func(t *testing.T) {
+		if rule, err := translator.Translate(nil); err != nil || rule != nil {
+			t.Error("Expected nil error and nil rule")
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package ppcerrors
+
+import "strings"
+
+type (
+	// TypedDefinition is a Definition whose errors carry a structured detail of type T,
+	// e.g.: the required and available amounts of an ErrBalanceTooLow error.
+	// It is the same definition as the *Definition it embeds, so that HasDefinition matches either of them,
+	// including for the errors created by the promoted methods, e.g.: NewSkip and WrapDefer, and the children created by NewChild.
+	TypedDefinition[T any] struct {
+		*Definition
+	}
+
+	// TypedErrorCode is an ErrorCode whose errors carry a structured detail of type T.
+	// It is the same error code as the *ErrorCode it embeds, see TypedDefinition.
+	TypedErrorCode[T any] struct {
+		*ErrorCode
+	}
+)
+
+// NewTypedDefinition creates and returns a pointer to an error definition instance whose errors carry a detail of type T.
+func NewTypedDefinition[T any](name string, desc string) *TypedDefinition[T] {
+	return &TypedDefinition[T]{Definition: NewDefinition(name, desc)}
+}
+
+// New creates a withDefinition error based on the current error definition d and attaches detail to it,
+// see Definition.New for the messages parameter.
+func (d *TypedDefinition[T]) New(detail T, messages ...string) error {
+	return created(&withDefinition{
+		def:   d,
+		msg:   strings.Join(messages, Config.MessagesSeparator),
+		pc:    getPCFromCaller(),
+		attrs: newAttrs(nil).withDetail(detail),
+	}, nil)
+}
+
+// Wrap wraps the given error with the current error definition d and attaches detail to it,
+// see Definition.Wrap for the messages parameter. If the cause error is nil, it returns nil.
+func (d *TypedDefinition[T]) Wrap(cause error, detail T, messages ...string) error {
+	if cause == nil {
+		return nil
+	}
+
+	return created(&withCause{
+		error: &withDefinition{
+			def:   d,
+			msg:   strings.Join(messages, Config.MessagesSeparator),
+			pc:    getPCFromCaller(),
+			attrs: newAttrs(cause).withDetail(detail),
+		},
+		cause: cause,
+	}, cause)
+}
+
+// NewTypedErrorCode creates and returns a pointer to an error code instance whose errors carry a detail of type T.
+func NewTypedErrorCode[T any](name string, code int, msg string) *TypedErrorCode[T] {
+	return &TypedErrorCode[T]{ErrorCode: NewErrorCode(name, code, msg)}
+}
+
+// New creates a withErrorCode error based on the current error code c and attaches detail to it,
+// see ErrorCode.New for the messages parameter.
+func (c *TypedErrorCode[T]) New(detail T, messages ...string) error {
+	return created(&withErrorCode{
+		errCode: c,
+		msg:     strings.Join(messages, Config.MessagesSeparator),
+		pc:      getPCFromCaller(),
+		attrs:   newAttrs(nil).withDetail(detail),
+	}, nil)
+}
+
+// Wrap wraps the given error with the current error code c and attaches detail to it,
+// see ErrorCode.Wrap for the messages parameter. If the cause error is nil, it returns nil.
+func (c *TypedErrorCode[T]) Wrap(cause error, detail T, messages ...string) error {
+	if cause == nil {
+		return nil
+	}
+
+	return created(&withCause{
+		error: &withErrorCode{
+			errCode: c,
+			msg:     strings.Join(messages, Config.MessagesSeparator),
+			pc:      getPCFromCaller(),
+			attrs:   newAttrs(cause).withDetail(detail),
+		},
+		cause: cause,
+	}, cause)
+}
+
+// DetailOf returns the detail of type T attached to the first layer in err's error chain
+// that contains the specified definition or error code target.
+// The second return value is false when no such layer exists or its detail is not of type T.
+func DetailOf[T any](err error, target interface{ Name() string }) (T, bool) {
+	var (
+		detail T
+		ok     bool
+	)
+	def, isDefinition := target.(Definer)
+	code, isErrorCode := target.(ErrorCoder)
+	Walk(err, func(l Layer) bool {
+		if (isDefinition && l.Definition != nil && sameDefinition(l.Definition, def)) ||
+			(isErrorCode && l.ErrorCode != nil && sameErrorCode(l.ErrorCode, code)) {
+			detail, ok = l.Detail.(T)
+			return false
+		}
+		return true
+	})
+	return detail, ok
+}
@@ -0,0 +1,104 @@
+package ppcerrors
+
+import (
+	"errors"
+	"testing"
+)
+
+type balanceDetail struct {
+	Required  int `json:"required"`
+	Available int `json:"available"`
+}
+
+func TestTypedDefinition(t *testing.T) {
+	def := NewTypedDefinition[balanceDetail]("ErrBalanceTooLow", "Balance too low")
+	detail := balanceDetail{Required: 100, Available: 30}
+
+	t.Run("Test New", func(t *testing.T) {
+		err := def.New(detail, "Buy failed")
+		expectedMsg := "ErrBalanceTooLow, Balance too low, Buy failed"
+		if err.Error() != expectedMsg {
+			t.Errorf("Expected error message to be '%s', got '%s'", expectedMsg, err.Error())
+		}
+		if !HasDefinition(err, def) {
+			t.Error("Expected HasDefinition to return true")
+		}
+	})
+
+	t.Run("Test Wrap", func(t *testing.T) {
+		err := Wrap(def.Wrap(errors.New("root"), detail, "Buy failed"), "outer")
+		actual, ok := DetailOf[balanceDetail](err, def)
+		if !ok || actual != detail {
+			t.Errorf("Expected detail %v, got %v (%t)", detail, actual, ok)
+		}
+		if def.Wrap(nil, detail) != nil {
+			t.Error("Expected nil error when cause is nil")
+		}
+	})
+
+	t.Run("Test DetailOf with mismatched type or target", func(t *testing.T) {
+		err := def.New(detail)
+		if _, ok := DetailOf[string](err, def); ok {
+			t.Error("Expected DetailOf to return false for a mismatched type")
+		}
+		if _, ok := DetailOf[balanceDetail](err, NewDefinition("ErrOther", "Other")); ok {
+			t.Error("Expected DetailOf to return false for a missing target")
+		}
+	})
+
+	t.Run("Same definition as the embedded definition", func(t *testing.T) {
+		if !HasDefinition(def.New(detail), def.Definition) {
+			t.Error("Expected the embedded definition to match the errors of the typed definition")
+		}
+		if !HasDefinition(def.NewSkip(0), def) {
+			t.Error("Expected the typed definition to match the errors of the promoted methods")
+		}
+		if !HasDefinition(def.NewChild("ErrBalanceFrozen", "Balance frozen").New(), def) {
+			t.Error("Expected the typed definition to match the errors of its children")
+		}
+
+		buy := func() (err error) {
+			defer def.WrapDefer(&err)
+			return errors.New("root")
+		}
+		if !HasDefinition(buy(), def) {
+			t.Error("Expected the typed definition to match the errors wrapped by WrapDefer")
+		}
+		if _, ok := DetailOf[balanceDetail](def.New(detail), def.Definition); !ok {
+			t.Error("Expected DetailOf to find the detail with the embedded definition")
+		}
+	})
+}
+
+func TestTypedErrorCode(t *testing.T) {
+	errCode := NewTypedErrorCode[balanceDetail]("ErrBalanceTooLow", 402, "Balance too low")
+	detail := balanceDetail{Required: 100, Available: 30}
+
+	t.Run("Test New", func(t *testing.T) {
+		err := errCode.New(detail, "Buy failed")
+		expectedMsg := "ErrBalanceTooLow, Code=402, Msg=Balance too low, Buy failed"
+		if err.Error() != expectedMsg {
+			t.Errorf("Expected error message to be '%s', got '%s'", expectedMsg, err.Error())
+		}
+		if !HasErrorCode(err, errCode) {
+			t.Error("Expected HasErrorCode to return true")
+		}
+	})
+
+	t.Run("Test Wrap", func(t *testing.T) {
+		err := errCode.Wrap(errors.New("root"), detail)
+		actual, ok := DetailOf[balanceDetail](err, errCode)
+		if !ok || actual != detail {
+			t.Errorf("Expected detail %v, got %v (%t)", detail, actual, ok)
+		}
+	})
+
+	t.Run("Same error code as the embedded error code", func(t *testing.T) {
+		if !HasErrorCode(errCode.New(detail), errCode.ErrorCode) {
+			t.Error("Expected the embedded error code to match the errors of the typed error code")
+		}
+		if !HasErrorCode(errCode.NewChild("ErrBalanceFrozen", 423, "Balance frozen").New(), errCode) {
+			t.Error("Expected the typed error code to match the errors of its children")
+		}
+	})
+}
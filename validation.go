@@ -0,0 +1,123 @@
+package ppcerrors
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Violation is a problem with a single field of a request, see ValidationErrors.
+	Violation struct {
+		// Path of the field, e.g.: items[3].count, see Path.
+		Path string
+		// Err is the error describing the problem, created from a definition, with the field path=<Path>.
+		Err error
+	}
+
+	// ValidationErrors collects the violations found while validating a request, so that all of them are returned to the client at once
+	// instead of one at a time. Its zero value is ready to use, e.g.:
+	//
+	//	var v ppcerrors.ValidationErrors
+	//	if req.Name == "" {
+	//		v.Add("name", ErrRequired)
+	//	}
+	//	for i, item := range req.Items {
+	//		if item.Count > 10 {
+	//			v.Add(ppcerrors.Path("items", i, "count"), ErrTooLarge, "must be at most 10")
+	//		}
+	//	}
+	//	return v.Err(ErrBadRequest)
+	ValidationErrors struct {
+		violations []Violation
+	}
+
+	// withViolations is the cause of the error returned by ValidationErrors.Err, each violation is one of its causes.
+	withViolations struct {
+		multiCause
+		violations []Violation
+	}
+)
+
+// Path joins the elements of a field path, strings are joined by dots and integers are printed as indexes,
+// e.g.: Path("items", 3, "count") returns items[3].count.
+func Path(elems ...interface{}) string {
+	var b strings.Builder
+	for _, elem := range elems {
+		switch e := elem.(type) {
+		case int:
+			b.WriteString("[" + strconv.Itoa(e) + "]")
+		default:
+			if b.Len() > 0 {
+				b.WriteString(".")
+			}
+			b.WriteString(fmt.Sprint(e))
+		}
+	}
+	return b.String()
+}
+
+// Add records a violation of the field at path created from the definition def, the messages parameter is used as in Definition.New.
+// When Config.Caller == true, the caller of Add is recorded.
+func (v *ValidationErrors) Add(path string, def Definer, messages ...string) {
+	v.violations = append(v.violations, Violation{
+		Path: path,
+		Err: created(&withDefinition{
+			def:   def,
+			msg:   strings.Join(messages, Config.MessagesSeparator),
+			pc:    getPCFromCaller(),
+			attrs: newAttrs(nil).withFields(F("path", path)),
+		}, nil),
+	})
+}
+
+// AddError records err as a violation of the field at path, e.g.: an error returned by the validator of a nested object.
+// AddError does nothing when err is nil.
+func (v *ValidationErrors) AddError(path string, err error) {
+	if err == nil {
+		return
+	}
+	v.violations = append(v.violations, Violation{Path: path, Err: WithFields(err, F("path", path))})
+}
+
+// Len returns the number of violations recorded.
+func (v *ValidationErrors) Len() int {
+	return len(v.violations)
+}
+
+// Err returns an error of the error code code, e.g.: ErrBadRequest with the code 400, whose causes are all the violations recorded,
+// so that HasDefinition reports any of their definitions, Violations returns them, and MarshalJSON and NewProblem list them.
+// The messages parameter is used as in ErrorCode.Wrap, and when Config.Caller == true, the caller of Err is recorded.
+// Err returns nil when no violation is recorded.
+func (v *ValidationErrors) Err(code ErrorCoder, messages ...string) error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+
+	errs := make([]error, 0, len(v.violations))
+	for _, violation := range v.violations {
+		errs = append(errs, violation.Err)
+	}
+	cause := &withViolations{
+		multiCause: multiCause{label: "violation", errs: errs},
+		violations: append([]Violation(nil), v.violations...),
+	}
+	return created(&withCause{
+		error: &withErrorCode{
+			errCode: code,
+			msg:     strings.Join(messages, Config.MessagesSeparator),
+			pc:      getPCFromCaller(),
+			attrs:   newAttrs(cause),
+		},
+		cause: cause,
+	}, cause)
+}
+
+// Violations returns the violations of the error returned by ValidationErrors.Err in err's chain, or nil when there is none.
+func Violations(err error) []Violation {
+	var e *withViolations
+	if As(err, &e) {
+		return e.violations
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package ppcerrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		elems    []interface{}
+		expected string
+	}{
+		{[]interface{}{"name"}, "name"},
+		{[]interface{}{"items", 3, "count"}, "items[3].count"},
+		{[]interface{}{"matrix", 1, 2}, "matrix[1][2]"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if path := Path(tt.elems...); path != tt.expected {
+			t.Errorf("Expected '%s', got '%s'", tt.expected, path)
+		}
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	errRequired := NewDefinition("ErrRequired", "Field is required")
+	errTooLarge := NewDefinition("ErrTooLarge", "Value is too large")
+	errUnknown := NewDefinition("ErrUnknown", "Unknown error")
+	errBadRequest := NewErrorCode("ErrBadRequest", 400, "Bad request")
+
+	newErr := func() error {
+		var v ValidationErrors
+		v.Add("name", errRequired)
+		v.Add(Path("items", 3, "count"), errTooLarge, "must be at most 10")
+		v.AddError("email", WithPublicMessage(errRequired.New(), "Email is required"))
+		v.AddError("ignored", nil)
+		return v.Err(errBadRequest)
+	}
+
+	t.Run("No violation", func(t *testing.T) {
+		var v ValidationErrors
+		if err := v.Err(errBadRequest); err != nil {
+			t.Errorf("Expected nil, got %v", err)
+		}
+	})
+
+	t.Run("Collect every violation", func(t *testing.T) {
+		err := newErr()
+		expected := "ErrBadRequest, Code=400, Msg=Bad request <= ErrRequired, Field is required, path=name; " +
+			"ErrTooLarge, Value is too large, must be at most 10, path=items[3].count; ErrRequired, Field is required, path=email"
+		if err.Error() != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, err.Error())
+		}
+
+		violations := Violations(err)
+		if len(violations) != 3 {
+			t.Fatalf("Expected 3 violations, got %d", len(violations))
+		}
+		for i, path := range []string{"name", "items[3].count", "email"} {
+			if violations[i].Path != path {
+				t.Errorf("Expected the path '%s', got '%s'", path, violations[i].Path)
+			}
+		}
+	})
+
+	t.Run("Answer HasDefinition for any violation", func(t *testing.T) {
+		err := Wrap(newErr(), "CreateOrder failed")
+		if !HasDefinition(err, errRequired) || !HasDefinition(err, errTooLarge) {
+			t.Errorf("Expected the definitions of the violations, got %v", err)
+		}
+		if HasDefinition(err, errUnknown) {
+			t.Errorf("Expected no ErrUnknown, got %v", err)
+		}
+		if !HasErrorCode(err, errBadRequest) {
+			t.Errorf("Expected ErrBadRequest, got %v", err)
+		}
+	})
+
+	t.Run("Print every violation with %+v", func(t *testing.T) {
+		Config.Caller = true
+		defer func() { Config.Caller = false }()
+
+		output := fmt.Sprintf("%+v", newErr())
+		if strings.Count(output, "violation: ") != 3 || !strings.Contains(output, "violation: ErrTooLarge, Value is too large, must be at most 10, path=items[3].count\n"+
+			"    at github.com/ppc-games/ppcerrors.TestValidationErrors.func1") {
+			t.Errorf("Expected each violation and its location, got '%s'", output)
+		}
+	})
+
+	t.Run("List the violations in JSON", func(t *testing.T) {
+		b, err := json.Marshal(newErr())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got struct {
+			Violations []jsonViolation `json:"violations"`
+		}
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		expected := []jsonViolation{
+			{Path: "name", Name: "ErrRequired", Message: "ErrRequired, Field is required, path=name"},
+			{Path: "items[3].count", Name: "ErrTooLarge", Message: "ErrTooLarge, Value is too large, must be at most 10, path=items[3].count"},
+			{Path: "email", Name: "ErrRequired", Message: "ErrRequired, Field is required, path=email"},
+		}
+		if !reflect.DeepEqual(got.Violations, expected) {
+			t.Errorf("Expected %v, got %v", expected, got.Violations)
+		}
+	})
+
+	t.Run("List the violations in the problem", func(t *testing.T) {
+		p := NewProblem(newErr())
+		expected := Problem{Type: "ErrBadRequest", Title: "Bad request", Status: 400, Code: 400, Violations: []ProblemViolation{
+			{Path: "name", Name: "ErrRequired"},
+			{Path: "items[3].count", Name: "ErrTooLarge"},
+			{Path: "email", Name: "ErrRequired", Message: "Email is required"},
+		}}
+		if !reflect.DeepEqual(*p, expected) {
+			t.Errorf("Expected %v, got %v", expected, *p)
+		}
+	})
+
+	t.Run("No violations outside validation errors", func(t *testing.T) {
+		if violations := Violations(errors.New("mock mongodb error")); violations != nil {
+			t.Errorf("Expected nil, got %v", violations)
+		}
+	})
+}
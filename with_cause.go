@@ -2,8 +2,6 @@ package ppcerrors
 
 import (
 	"fmt"
-	"io"
-	"strings"
 )
 
 // withCause implements the error interface.
@@ -12,16 +10,15 @@ import (
 type withCause struct {
 	error
 	cause error
+	// origin is the error this one was copied from by amend, see isOrigin.
+	origin error
 }
 
-// Error prints the error message of the current error e, followed by the error message of the cause wrapped by e.
+// Error prints the error message of the current error e, followed by the error message of the cause wrapped by e,
+// in the layout of the formatter of e, see Formatter.
 // e.g.: ErrNilUser, User information is empty <= cause's Error().
 func (e *withCause) Error() string {
-	var b strings.Builder
-	b.WriteString(e.error.Error())
-	b.WriteString(Config.ErrorChainSeparator)
-	b.WriteString(e.cause.Error())
-	return b.String()
+	return formatterOf(e).Text(chainOf(e, false))
 }
 
 // Cause returns the cause wrapped by the current error e.
@@ -36,22 +33,14 @@ func (e *withCause) Unwrap() error {
 	return e.cause
 }
 
+// Is reports whether target is the error e was copied from, e.g.: by MarkLogged, see isOrigin.
+// It implements the Is interface in the errors standard library.
+func (e *withCause) Is(target error) bool {
+	return isOrigin(e.origin, target)
+}
+
 // Format will print the detailed error reasons of each layer of cause in the error chain when verb == %+v.
 // Otherwise, it will print the error message of the current error.
 func (e *withCause) Format(s fmt.State, verb rune) {
-	switch verb {
-	case 'v':
-		if s.Flag('+') {
-			// 先打印当前错误
-			_, _ = fmt.Fprintf(s, "%+v", e.error)
-
-			// 然后打印被包装的 cause
-			_, _ = fmt.Fprintf(s, "\ncause: %+v", e.cause)
-
-			return
-		}
-		fallthrough
-	case 's', 'q':
-		_, _ = io.WriteString(s, e.Error())
-	}
+	formatChain(e, s, verb)
 }
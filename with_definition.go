@@ -2,26 +2,27 @@ package ppcerrors
 
 import (
 	"fmt"
-	"strings"
 )
 
 type (
 	// WithDefinitioner defines the Definition() method to return the error definition contained in the error.
+	// Error types outside this package can implement it to be recognized by HasDefinition and Walk.
 	WithDefinitioner interface {
-		Definition() *definition
+		Definition() Definer
 	}
 
 	// withDefinition is an error that contains a definition to distinguish it from other errors.
 	// The msg field is used to store additional error information attached when the withDefinition error is created,
 	// The pc field is the program counter when the withDefinition error was created, which can be used to print the function name + file name + line number when the error was created.
 	withDefinition struct {
-		def *definition
+		def Definer
 		msg string
 		pc  uintptr
+		attrs
 	}
 )
 
-func (e *withDefinition) Definition() *definition {
+func (e *withDefinition) Definition() Definer {
 	return e.def
 }
 
@@ -29,25 +30,18 @@ func (e *withDefinition) PC() uintptr {
 	return e.pc
 }
 
-// Error prints name, desc, and msg in turn,
+func (e *withDefinition) Message() string {
+	return e.msg
+}
+
+// Error prints name, desc, msg, and fields in turn in the layout of the formatter of e, see Formatter.
 // e.g.: ErrNilUser, User information is empty, something wrong.
 func (e *withDefinition) Error() string {
-	var b strings.Builder
-
-	b.WriteString(e.def.name)
-	b.WriteString(Config.MessagesSeparator)
-	b.WriteString(e.def.desc)
-
-	if e.msg != "" {
-		b.WriteString(Config.MessagesSeparator)
-		b.WriteString(e.msg)
-	}
-
-	return b.String()
+	return formatterOf(e).Text(chainOf(e, false))
 }
 
 // Format formats the error message according to the given format specifier.
 // It implements the fmt.Formatter interface.
 func (e *withDefinition) Format(s fmt.State, verb rune) {
-	formatWithPC(e, s, verb)
+	formatChain(e, s, verb)
 }
@@ -5,7 +5,7 @@ import (
 )
 
 func TestWithDefinition(t *testing.T) {
-	def := &definition{
+	def := &Definition{
 		name: "ErrInvalidConfig",
 		desc: "Invalid configuration",
 	}
@@ -2,14 +2,13 @@ package ppcerrors
 
 import (
 	"fmt"
-	"strconv"
-	"strings"
 )
 
 type (
 	// WithErrorCoder defines the ErrorCode() method, which is used to return the error code contained in an error.
+	// Error types outside this package can implement it to be recognized by HasErrorCode and Walk.
 	WithErrorCoder interface {
-		ErrorCode() *errorCode
+		ErrorCode() ErrorCoder
 	}
 
 	// withErrorCode is an error that contains an error code to distinguish it from other errors.
@@ -18,13 +17,14 @@ type (
 	// The msg field is used to store additional error information attached when the withErrorCode error is created,
 	// The pc field is the program counter when the withErrorCode error was created, which can be used to print the function name + file name + line number when the error was created.
 	withErrorCode struct {
-		errCode *errorCode
+		errCode ErrorCoder
 		msg     string
 		pc      uintptr
+		attrs
 	}
 )
 
-func (e *withErrorCode) ErrorCode() *errorCode {
+func (e *withErrorCode) ErrorCode() ErrorCoder {
 	return e.errCode
 }
 
@@ -32,28 +32,19 @@ func (e *withErrorCode) PC() uintptr {
 	return e.pc
 }
 
-// Error prints errCode.name, errCode.code, errCode.msg, and msg in turn,
+func (e *withErrorCode) Message() string {
+	return e.msg
+}
+
+// Error prints errCode.name, errCode.code, errCode.msg, msg, and fields in turn in the layout of the formatter of e, see Formatter.
 // e.g.: ErrUnauthorized, Code=10002, Msg=Unauthorized, something wrong;
 // e.g.: ErrUnauthorized, Code=10002, Msg=Unauthorized.
 func (e *withErrorCode) Error() string {
-	var b strings.Builder
-
-	b.WriteString(e.errCode.name)
-	b.WriteString(", Code=")
-	b.WriteString(strconv.Itoa(e.errCode.code))
-	b.WriteString(", Msg=")
-	b.WriteString(e.errCode.msg)
-
-	if e.msg != "" {
-		b.WriteString(Config.MessagesSeparator)
-		b.WriteString(e.msg)
-	}
-
-	return b.String()
+	return formatterOf(e).Text(chainOf(e, false))
 }
 
 // Format formats the error message according to the given format specifier.
 // It implements the fmt.Formatter interface.
 func (e *withErrorCode) Format(s fmt.State, verb rune) {
-	formatWithPC(e, s, verb)
+	formatChain(e, s, verb)
 }
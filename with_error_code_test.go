@@ -5,7 +5,7 @@ import (
 )
 
 func TestWithErrorCode(t *testing.T) {
-	errCode := &errorCode{
+	errCode := &ErrorCode{
 		name: "ErrUnauthorized",
 		code: 401,
 		msg:  "Unauthorized",
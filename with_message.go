@@ -10,19 +10,24 @@ import (
 type withMessage struct {
 	msg string
 	pc  uintptr
+	attrs
 }
 
 func (e *withMessage) PC() uintptr {
 	return e.pc
 }
 
-// Error returns e.msg.
-func (e *withMessage) Error() string {
+func (e *withMessage) Message() string {
 	return e.msg
 }
 
+// Error returns e.msg followed by the fields attached to e, if any, in the layout of the formatter of e, see Formatter.
+func (e *withMessage) Error() string {
+	return formatterOf(e).Text(chainOf(e, false))
+}
+
 // Format formats the error message according to the given format specifier.
 // It implements the fmt.Formatter interface.
 func (e *withMessage) Format(s fmt.State, verb rune) {
-	formatWithPC(e, s, verb)
+	formatChain(e, s, verb)
 }